@@ -0,0 +1,153 @@
+// Copyright © 2023 The Gomon Project.
+
+/*
+Package deps implements the analysis of the godep command, which examines the source files
+of a Go language module to graph the dependencies of its packages on packages of the Go
+standard library, of imports, and of those that are vendored.
+
+Analyze parses a module and its imports into the trees that the godep command reports, and
+the Result serializes the package dependencies as a Graphviz node graph. Before the graph is
+serialized, its model of package nodes and dependency edges is passed to each
+GraphTransformer added with RegisterTransformer, which may relabel, recolor, filter, or
+annotate the nodes and edges.
+
+Each Analyze begins with the clean state of a fresh analyzer, and each serialization with
+that of a fresh layout, so that a process may analyze one module after another. The options
+of an analysis, and the location of its module, apply only while it runs and while its Result
+serializes.
+*/
+package deps
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// Options configure Analyze as the corresponding godep command line flags. A zero value
+	// keeps the flag's default.
+	Options struct {
+		Qualify string        // qualify the identifiers in the trees with the package "name" or import "path"
+		Tests   bool          // include the _test.go files
+		GOOS    string        // target operating system of build constraints
+		GOARCH  string        // target architecture of build constraints
+		Tags    string        // comma separated additional build tags
+		Timeout time.Duration // time limit of the analysis, after which the trees are partial
+		Jobs    int           // package directories to parse concurrently
+		Skip    string        // comma separated names of additional directories to skip
+	}
+
+	// Result holds the trees of an analysis, keyed by name: IMPORTS, INTERFACES, TYPES,
+	// VALUES, FUNCTIONS, DEFINES, REFERENCES, and IMPLEMENTS.
+	Result struct {
+		Trees map[string]gocore.Tree[string, string, any]
+		a     *analyzer
+		lks   linkset
+		dir   string
+		opts  options
+	}
+)
+
+// Analyze parses the module of a directory and its imports.
+func Analyze(dir string, opts Options) (*Result, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, gocore.Error("directory", err)
+	}
+	o := opts.options()
+	a, err := analysis(context.Background(), abs, o)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Result{
+		Trees: map[string]gocore.Tree[string, string, any]{},
+		a:     a,
+		lks:   links(a.refs),
+		dir:   abs,
+		opts:  o,
+	}
+	for t := range TREES {
+		r.Trees[names[t]] = a.trees[t]
+	}
+	return r, nil
+}
+
+// Graphviz transforms the graph of the package dependencies with the registered
+// GraphTransformers and serializes it for the Graphviz dot command.
+func (r *Result) Graphviz() (string, error) {
+	restore, err := within(r.opts, r.dir)
+	defer restore()
+	if err != nil {
+		return "", err
+	}
+	g := r.a.model(r.lks)
+	if err := r.a.transform(g); err != nil {
+		return "", err
+	}
+	return r.a.nodegraph(g)
+}
+
+// options sets a copy of the flags to the options.
+func (opts Options) options() options {
+	o := flags
+	for _, v := range []struct {
+		flag  *string
+		value string
+	}{
+		{&o.qualify, opts.Qualify},
+		{&o.goos, opts.GOOS},
+		{&o.goarch, opts.GOARCH},
+		{&o.tags, opts.Tags},
+		{&o.skip, opts.Skip},
+	} {
+		if v.value != "" {
+			*v.flag = v.value
+		}
+	}
+	if opts.Tests {
+		o.tests = true
+	}
+	if opts.Timeout > 0 {
+		o.timeout = opts.Timeout
+	}
+	if opts.Jobs > 0 {
+		o.jobs = opts.Jobs
+	}
+	return o
+}
+
+// analysis parses the module of a directory in process with a fresh analyzer, as the options
+// configure it.
+func analysis(ctx context.Context, dir string, o options) (*analyzer, error) {
+	restore, err := within(o, dir)
+	defer restore()
+	if err != nil {
+		return nil, err
+	}
+	a := newanalyzer()
+	if err := a.analyze(ctx); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// within sets the flags to the options and locates the module of a directory, returning the
+// function that restores the flags, the directory, and the module location that were set before.
+func within(o options, dir string) (func(), error) {
+	saved, wd := flags, cwd
+	mod, dm, dmap, ws, req := gomod, dirmod, dirmap, workspace, required
+	restore := func() {
+		flags, cwd = saved, wd
+		gomod, dirmod, dirmap, workspace, required = mod, dm, dmap, ws, req
+	}
+	flags = o
+	if err := validate(); err != nil {
+		return restore, err
+	}
+	cwd = dir
+	return restore, locate("")
+}
@@ -0,0 +1,113 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// stamp matches the graph label, which times the serialization.
+var stamp = regexp.MustCompile(`label="\\G [^"]*"`)
+
+// fixture locates a module of the testdata directory, isolated from the workspace and flags of
+// the environment.
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "")
+	dir, err := filepath.Abs(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// analyze analyzes a module of the testdata directory.
+func analyze(t *testing.T, name string, opts Options) *Result {
+	t.Helper()
+	r, err := Analyze(fixture(t, name), opts)
+	if err != nil {
+		t.Fatalf("Analyze(%s) error = %v", name, err)
+	}
+	return r
+}
+
+// enter locates the module of an analysis for the rest of a test, e.g. to check its linkset.
+func enter(t *testing.T, r *Result) {
+	t.Helper()
+	restore, err := within(r.opts, r.dir)
+	t.Cleanup(restore)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// graphviz serializes the graph of an analysis without its timestamp.
+func graphviz(t *testing.T, r *Result) string {
+	t.Helper()
+	g, err := r.Graphviz()
+	if err != nil {
+		t.Fatalf("Graphviz() error = %v", err)
+	}
+	return stamp.ReplaceAllString(g, "")
+}
+
+func TestAnalyzeReproducible(t *testing.T) {
+	first := graphviz(t, analyze(t, "graph", Options{}))
+	if !strings.Contains(first, `"example.com/graph: c" -> "example.com/graph: b" [dir=back`) {
+		t.Fatalf("Graphviz() lacks the dependency of b on c:\n%s", first)
+	}
+	for _, test := range []struct {
+		name   string
+		before func()
+	}{
+		{"again", func() {}},
+		{"after qualifying by path with tests", func() {
+			graphviz(t, analyze(t, "graph", Options{Qualify: "path", Tests: true}))
+		}},
+		{"after another module", func() {
+			graphviz(t, analyze(t, "generic", Options{}))
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.before()
+			if again := graphviz(t, analyze(t, "graph", Options{})); again != first {
+				t.Errorf("Graphviz() differs from the first analysis:\n%s\nwant:\n%s", again, first)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRestoresFlags(t *testing.T) {
+	saved := flags
+	analyze(t, "graph", Options{Qualify: "path", Tests: true, GOOS: "windows"})
+	if flags.qualify != saved.qualify || flags.tests != saved.tests || flags.goos != saved.goos {
+		t.Errorf("Analyze() left flags qualify=%s tests=%t goos=%s, want %s %t %s",
+			flags.qualify, flags.tests, flags.goos, saved.qualify, saved.tests, saved.goos)
+	}
+}
+
+func TestAnalyzeIsolated(t *testing.T) {
+	mod, dir, skip := gomod, dirmod, flags.skip
+	defines := func(r *Result, def string) bool {
+		_, ok := r.Trees["DEFINES"][def]
+		return ok
+	}
+
+	if r := analyze(t, "graph", Options{Skip: "b"}); defines(r, "b.Do") || !defines(r, "d.Do") {
+		t.Errorf("Analyze(graph) with -skip b DEFINES = %v, want d.Do and not b.Do", r.Trees["DEFINES"])
+	}
+	if r := analyze(t, "imports", Options{}); !defines(r, "e.G") || !defines(r, "a.F") {
+		t.Errorf("Analyze(imports) DEFINES = %v, want e.G and a.F", r.Trees["DEFINES"])
+	}
+	if r := analyze(t, "graph", Options{}); !defines(r, "b.Do") {
+		t.Errorf("Analyze(graph) after -skip b DEFINES = %v, want b.Do", r.Trees["DEFINES"])
+	}
+	if gomod != mod || dirmod != dir || flags.skip != skip {
+		t.Errorf("Analyze() left module %s in %s and -skip %q, want %s in %s and %q",
+			gomod, dirmod, flags.skip, mod, dir, skip)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+// apis assembles the exported API of each module package directory from the DEFINES,
+// TYPES, FUNCTIONS, and VALUES trees. Each entry is an unqualified declaration such as
+// "func Name(int) error", "type Name", a "Name.Method(...)" of a type, or "value Name".
+// Since the trees qualify identifiers by package name, same named packages share entries.
+func (a *analyzer) apis() map[string]map[string]struct{} {
+	api := map[string]map[string]struct{}{}
+	for def, dirs := range a.defs {
+		pkg, name := qualified(def)
+		for dir := range dirs {
+			if _, err := gocore.Subdir(dirmod, dir); err != nil {
+				continue
+			}
+			if _, ok := api[dir]; !ok {
+				api[dir] = map[string]struct{}{}
+			}
+			if flds, ok := a.typs[def]; ok {
+				api[dir]["type "+name] = struct{}{}
+				for fld := range flds {
+					api[dir][name+"."+fld] = struct{}{}
+				}
+			} else if _, ok := a.ifcs[def]; ok {
+				api[dir]["type "+name] = struct{}{}
+				for mth := range a.ifcs[def] {
+					api[dir][name+"."+mth] = struct{}{}
+				}
+			} else if _, ok := a.vals[def]; ok {
+				api[dir]["value "+name] = struct{}{}
+			}
+			for fnc := range a.fncs {
+				if strings.HasPrefix(fnc, pkg+"."+name+"(") {
+					api[dir]["func "+strings.TrimPrefix(fnc, pkg+".")] = struct{}{}
+				}
+			}
+		}
+	}
+	return api
+}
+
+// similarities reports pairs of module packages whose exported APIs have a
+// Jaccard similarity of at least the -similar threshold, as candidates for unification.
+func (a *analyzer) similarities() {
+	api := a.apis()
+	var dirs []string
+	for dir, decls := range api {
+		if len(decls) > 0 {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	fmt.Fprintln(os.Stderr, "==== SIMILAR APIS ====")
+	for i, x := range dirs {
+		for _, y := range dirs[i+1:] {
+			var common int
+			for decl := range api[x] {
+				if _, ok := api[y][decl]; ok {
+					common++
+				}
+			}
+			similarity := float64(common) / float64(len(api[x])+len(api[y])-common)
+			if similarity >= flags.similar {
+				fmt.Fprintf(os.Stderr, "%.2f\t%s\t%s\n", similarity, importpath(x), importpath(y))
+			}
+		}
+	}
+}
+
+// similarinterfaces reports pairs of interfaces declared in different module packages whose
+// method names have a Jaccard similarity of at least the -similar-interfaces threshold, as
+// candidates for consolidation into a shared interface. Names rather than signatures are
+// compared, since each package's declarations qualify the types of the signatures differently.
+func (a *analyzer) similarinterfaces() {
+	type declared struct {
+		dir, name string
+		mths      map[string]struct{}
+	}
+	var decls []declared
+	for ifc, mths := range a.ifcs {
+		_, name := qualified(ifc)
+		names := map[string]struct{}{}
+		for mth := range mths {
+			if n, _, ok := strings.Cut(mth, "("); ok {
+				names[n] = struct{}{}
+			}
+		}
+		if len(names) == 0 {
+			continue // any interface is not a conceptual duplicate
+		}
+		for dir := range a.defs[ifc] {
+			if _, err := gocore.Subdir(dirmod, dir); err == nil {
+				decls = append(decls, declared{dir: dir, name: name, mths: names})
+			}
+		}
+	}
+	sort.Slice(decls, func(i, j int) bool {
+		return decls[i].dir < decls[j].dir ||
+			decls[i].dir == decls[j].dir && decls[i].name < decls[j].name
+	})
+
+	fmt.Fprintln(os.Stderr, "==== SIMILAR INTERFACES ====")
+	for i, x := range decls {
+		for _, y := range decls[i+1:] {
+			if x.dir == y.dir {
+				continue
+			}
+			var common []string
+			for mth := range x.mths {
+				if _, ok := y.mths[mth]; ok {
+					common = append(common, mth)
+				}
+			}
+			similarity := float64(len(common)) / float64(len(x.mths)+len(y.mths)-len(common))
+			if similarity >= flags.similarInterfaces {
+				sort.Strings(common)
+				fmt.Fprintf(os.Stderr, "%.2f\t%s.%s\t%s.%s\t%s\n",
+					similarity,
+					importpath(x.dir), x.name,
+					importpath(y.dir), y.name,
+					strings.Join(common, ","),
+				)
+			}
+		}
+	}
+}
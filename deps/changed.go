@@ -0,0 +1,97 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+// changes identifies the module package directories with go files changed since a git revision.
+func changes(rev string) (map[string]struct{}, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--relative", rev, "--")
+	cmd.Dir = dirmod
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, gocore.Error("git diff", err, map[string]string{
+			"revision": rev,
+			"stderr":   stderr.String(),
+		})
+	}
+
+	dirs := map[string]struct{}{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if name := sc.Text(); strings.HasSuffix(name, ".go") {
+			dirs[filepath.Join(dirmod, filepath.Dir(name))] = struct{}{}
+		}
+	}
+	return dirs, nil
+}
+
+// impact reduces the dependencies to those among the changed packages, everything they
+// depend on, and everything that depends on them, and highlights the changed packages.
+func (lks linkset) impact(changed map[string]struct{}, highlights map[string]string) {
+	succs := map[string][]string{}
+	preds := map[string][]string{}
+	for lk := range lks {
+		succs[lk.from] = append(succs[lk.from], lk.to)
+		preds[lk.to] = append(preds[lk.to], lk.from)
+	}
+
+	impacted := map[string]struct{}{}
+	for _, adjacent := range []map[string][]string{succs, preds} {
+		var stack []string
+		visited := map[string]struct{}{}
+		for dir := range changed {
+			visited[dir] = struct{}{}
+			stack = append(stack, dir)
+		}
+		for len(stack) > 0 {
+			dir := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			impacted[dir] = struct{}{}
+			for _, next := range adjacent[dir] {
+				if _, ok := visited[next]; !ok {
+					visited[next] = struct{}{}
+					stack = append(stack, next)
+				}
+			}
+		}
+	}
+
+	for lk := range lks {
+		_, from := impacted[lk.from]
+		_, to := impacted[lk.to]
+		if !from || !to {
+			delete(lks, lk)
+		}
+	}
+
+	for dir := range changed {
+		highlights[dir] = "color=red penwidth=4"
+	}
+}
+
+// revision reports the git commit of the module's checkout, or "" with a warning if git cannot determine it.
+func revision() string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dirmod
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		gocore.Error("git rev-parse", err, map[string]string{
+			"stderr": stderr.String(),
+		}).Warn()
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
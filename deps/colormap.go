@@ -0,0 +1,60 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/zosmac/gocore"
+)
+
+// loadcolors reads the -color-map file of node names and colors, if it exists, into the
+// palette, so that its nodes keep their colors across related graphs.
+func (a *analyzer) loadcolors(name string) error {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // created by savecolors
+	} else if err != nil {
+		return gocore.Error("color-map", err)
+	}
+	cm := map[string]string{}
+	if err := json.Unmarshal(data, &cm); err != nil {
+		return gocore.Error("color-map", err, map[string]string{
+			"file": name,
+		})
+	}
+	for node, c := range cm {
+		a.palette[node] = c
+	}
+	return nil
+}
+
+// savecolors writes the -color-map file with the colors of the nodes of the Graph, retaining
+// the colors loaded for nodes absent from this graph.
+func (a *analyzer) savecolors(name string, g *Graph) error {
+	cm := map[string]string{}
+	for node, c := range a.palette {
+		cm[node] = c
+	}
+	for _, n := range g.Nodes {
+		cm[n.Name] = n.Color
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return gocore.Error("color-map", err)
+	}
+	err = encoder(f).Encode(cm)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return gocore.Error("color-map", err, map[string]string{
+			"file": name,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// structure summarizes a module's internal package graph and public API for comparison.
+	structure struct {
+		pkgs map[string]struct{} // module packages, relative to the module directory
+		deps map[string]struct{} // dependencies of module packages, "from -> to"
+		api  map[string]struct{} // exported symbols of module packages with their declarations
+	}
+)
+
+// compare reports the structural differences between two checkouts of a module, given as
+// "pathA,pathB": the packages, package dependencies, and exported symbols added or removed.
+func compare(ctx context.Context, w io.Writer, pths string) error {
+	a, b, ok := strings.Cut(pths, ",")
+	if !ok || a == "" || b == "" {
+		return gocore.Error("compare-modules", errors.New("expected pathA,pathB"), map[string]string{
+			"compare-modules": pths,
+		})
+	}
+
+	var sides [2]structure
+	for i, pth := range []string{a, b} {
+		var err error
+		if sides[i], err = analyzed(ctx, pth); err != nil {
+			return err
+		}
+	}
+
+	for _, diff := range []struct {
+		title string
+		a, b  map[string]struct{}
+	}{
+		{"PACKAGES", sides[0].pkgs, sides[1].pkgs},
+		{"DEPENDENCIES", sides[0].deps, sides[1].deps},
+		{"EXPORTS", sides[0].api, sides[1].api},
+	} {
+		fmt.Fprintf(w, "==== %s ====\n", diff.title)
+		for _, line := range difference(diff.a, diff.b) {
+			fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// analyzed analyzes a module checkout with the options of the comparison and summarizes its trees.
+func analyzed(ctx context.Context, pth string) (structure, error) {
+	dir, err := filepath.Abs(pth)
+	if err != nil {
+		return structure{}, gocore.Error("compare-modules", err)
+	}
+	module := gocore.Module(dir)
+	if module.Dir == "" {
+		return structure{}, gocore.Error("go.mod", ErrNoModule, map[string]string{
+			"directory": dir,
+		})
+	}
+
+	a, err := analysis(ctx, dir, flags)
+	if err != nil {
+		return structure{}, err
+	}
+
+	rel := func(abs string) (string, bool) {
+		if pkg, err := gocore.Subdir(module.Dir, abs); err == nil {
+			return filepath.ToSlash(pkg), true
+		}
+		for _, root := range []string{dirstd, dirimps} {
+			if pkg, err := gocore.Subdir(root, abs); err == nil {
+				return filepath.ToSlash(pkg), false
+			}
+		}
+		return abs, false
+	}
+
+	s := structure{
+		pkgs: map[string]struct{}{},
+		deps: map[string]struct{}{},
+		api:  map[string]struct{}{},
+	}
+	for _, dirs := range a.trees[DEFINES] {
+		for abs := range dirs {
+			if pkg, ok := rel(abs); ok {
+				s.pkgs[pkg] = struct{}{}
+			}
+		}
+	}
+	for _, rdirs := range a.trees[REFERENCES] {
+		for rabs, ddirs := range rdirs {
+			from, ok := rel(rabs)
+			if !ok {
+				continue
+			}
+			s.pkgs[from] = struct{}{}
+			for dabs := range ddirs {
+				if to, _ := rel(dabs); to != from {
+					s.deps[from+" -> "+to] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, t := range []TREE{INTERFACES, TYPES, VALUES, FUNCTIONS} {
+		for name, decls := range a.trees[t] {
+			sym, _, _ := strings.Cut(name, "(") // a function's name precedes its signature
+			sym, _, _ = strings.Cut(sym, "[")
+			for abs := range a.trees[DEFINES][sym] {
+				if _, ok := rel(abs); ok { // defined by a module package
+					s.api[names[t]+" "+name] = struct{}{}
+					for decl := range decls {
+						s.api[names[t]+" "+name+" "+decl] = struct{}{}
+					}
+					break
+				}
+			}
+		}
+	}
+	return s, nil
+}
+
+// difference lists the entries removed from a, prefixed with "-", and added in b, prefixed with "+".
+func difference(a, b map[string]struct{}) []string {
+	var lines []string
+	for entry := range a {
+		if _, ok := b[entry]; !ok {
+			lines = append(lines, "- "+entry)
+		}
+	}
+	for entry := range b {
+		if _, ok := a[entry]; !ok {
+			lines = append(lines, "+ "+entry)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i][2:] < lines[j][2:] || lines[i][2:] == lines[j][2:] && lines[i] < lines[j]
+	})
+	return lines
+}
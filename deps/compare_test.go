@@ -0,0 +1,35 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	graph, cycle := fixture(t, "graph"), fixture(t, "cycle")
+	mod, dir := gomod, dirmod
+	var buf bytes.Buffer
+	if err := compare(context.Background(), &buf, graph+","+cycle); err != nil {
+		t.Fatalf("compare() error = %v", err)
+	}
+	packages, rest, _ := strings.Cut(buf.String(), "==== DEPENDENCIES ====\n")
+	dependencies, exports, _ := strings.Cut(rest, "==== EXPORTS ====\n")
+	if want := "==== PACKAGES ====\n+ .\n- c\n- d\n"; packages != want {
+		t.Errorf("compare() PACKAGES:\n%s\nwant:\n%s", packages, want)
+	}
+	for _, dep := range []string{"- a -> c", "+ b -> a", "- b -> c"} {
+		if !strings.Contains(dependencies, dep+"\n") {
+			t.Errorf("compare() DEPENDENCIES lack %q:\n%s", dep, dependencies)
+		}
+	}
+	if strings.Contains(exports, " fmt.") || !strings.Contains(exports, "- TYPES b.Thing\n") {
+		t.Errorf("compare() EXPORTS, want those of the module packages only:\n%s", exports)
+	}
+	if gomod != mod || dirmod != dir {
+		t.Errorf("compare() left module %s in %s, want %s in %s", gomod, dirmod, mod, dir)
+	}
+}
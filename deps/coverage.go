@@ -0,0 +1,119 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// coverage is the GraphTransformer that annotates each module package with the
+	// percentage of its statements that a Go coverage profile reports as covered.
+	coverage struct {
+		percents map[string]float64 // package import path:percent statements covered
+	}
+)
+
+// loadcoverage reads a Go coverage profile, as written by go test -coverprofile, and totals
+// the statements and covered statements of each package. A block that the profile lists
+// multiple times, e.g. when profiles are merged, is covered if any of its counts is nonzero.
+func loadcoverage(name string) (coverage, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return coverage{}, gocore.Error("coverage", err)
+	}
+	defer f.Close()
+
+	type block struct {
+		stmts   int
+		covered bool
+	}
+	blocks := map[string]block{} // file:start,end
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		fields := strings.Fields(line) // file:start,end stmts count
+		if len(fields) != 3 {
+			continue
+		}
+		stmts, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		b := blocks[fields[0]]
+		b.stmts = stmts
+		b.covered = b.covered || count > 0
+		blocks[fields[0]] = b
+	}
+	if err := sc.Err(); err != nil {
+		return coverage{}, gocore.Error("coverage", err)
+	}
+
+	stmts := map[string][2]int{} // package import path:[statements, covered statements]
+	for pos, b := range blocks {
+		file, _, _ := strings.Cut(pos, ":")
+		pkg := path.Dir(file)
+		s := stmts[pkg]
+		s[0] += b.stmts
+		if b.covered {
+			s[1] += b.stmts
+		}
+		stmts[pkg] = s
+	}
+
+	cov := coverage{percents: map[string]float64{}}
+	for pkg, s := range stmts {
+		if s[0] > 0 {
+			cov.percents[pkg] = 100 * float64(s[1]) / float64(s[0])
+		}
+	}
+	return cov, nil
+}
+
+// Transform labels each module package with its coverage, and emphasizes the packages that
+// are risk hotspots: foundations that multiple module packages depend on with under half of
+// their statements covered.
+func (cov coverage) Transform(g *Graph) error {
+	dependents := map[string]map[string]struct{}{}
+	for _, e := range g.Edges {
+		if _, ok := dependents[e.To]; !ok {
+			dependents[e.To] = map[string]struct{}{}
+		}
+		if n := g.Nodes[e.From]; n != nil && n.Cluster == gomod {
+			dependents[e.To][e.From] = struct{}{}
+		}
+	}
+
+	for dir, n := range g.Nodes {
+		if n.Cluster != gomod {
+			continue
+		}
+		pct, ok := cov.percents[importpath(dir)]
+		if !ok {
+			n.Label += "\nno coverage"
+			n.Tooltip += "\\nno coverage profiled"
+			continue
+		}
+		n.Label += fmt.Sprintf("\n%.1f%%", pct)
+		n.Tooltip += fmt.Sprintf("\\n%.1f%% of statements covered", pct)
+		if pct < 50 && len(dependents[dir]) > 1 {
+			n.Attrs = emphasize(n.Attrs, "color=red penwidth=3")
+			n.Tooltip += fmt.Sprintf("\\nrisk: %d dependents", len(dependents[dir]))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// cycles finds the strongly connected components of more than one module package in the
+// dependency graph with Tarjan's algorithm. Each cycle lists its package directories in order.
+func cycles(lks linkset) [][]string {
+	succs := map[string][]string{}
+	for _, lk := range lks.sorted() {
+		if lk.from != lk.to && lk.internal() {
+			succs[lk.from] = append(succs[lk.from], lk.to)
+		}
+	}
+
+	var (
+		index   = map[string]int{}
+		lowlink = map[string]int{}
+		onstack = map[string]bool{}
+		stack   []string
+		sccs    [][]string
+		connect func(string)
+	)
+	connect = func(pkg string) {
+		index[pkg] = len(index)
+		lowlink[pkg] = index[pkg]
+		stack = append(stack, pkg)
+		onstack[pkg] = true
+
+		for _, succ := range succs[pkg] {
+			if _, ok := index[succ]; !ok {
+				connect(succ)
+				lowlink[pkg] = min(lowlink[pkg], lowlink[succ])
+			} else if onstack[succ] {
+				lowlink[pkg] = min(lowlink[pkg], index[succ])
+			}
+		}
+
+		if lowlink[pkg] != index[pkg] {
+			return
+		}
+		var scc []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onstack[top] = false
+			scc = append(scc, top)
+			if top == pkg {
+				break
+			}
+		}
+		if len(scc) > 1 {
+			sort.Strings(scc)
+			sccs = append(sccs, scc)
+		}
+	}
+
+	var pkgs []string
+	for pkg := range succs {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		if _, ok := index[pkg]; !ok {
+			connect(pkg)
+		}
+	}
+
+	sort.Slice(sccs, func(i, j int) bool { return sccs[i][0] < sccs[j][0] })
+	return sccs
+}
+
+// condensation is the GraphTransformer that labels the node representing each strongly
+// connected component of the condensed graph with its member packages.
+type condensation [][]string
+
+// condense merges the dependencies of the packages of each strongly connected component
+// onto the component's first package, so that the dependencies between components are acyclic.
+func (lks linkset) condense(sccs [][]string) linkset {
+	reps := map[string]string{} // package directory:component's first package directory
+	for _, scc := range sccs {
+		for _, dir := range scc {
+			reps[dir] = scc[0]
+		}
+	}
+	rep := func(dir string) string {
+		if r, ok := reps[dir]; ok {
+			return r
+		}
+		return dir
+	}
+
+	condensed := linkset{}
+	for lk, syms := range lks {
+		lk = link{from: rep(lk.from), to: rep(lk.to)}
+		if lk.from == lk.to {
+			continue // within the component
+		}
+		if _, ok := condensed[lk]; !ok {
+			condensed[lk] = tree{}
+		}
+		for sym := range syms {
+			condensed[lk].Add(sym)
+		}
+	}
+	return condensed
+}
+
+// Transform labels each component's node with its count of packages and lists them in its tooltip.
+func (sccs condensation) Transform(g *Graph) error {
+	for _, scc := range sccs {
+		n, ok := g.Nodes[scc[0]]
+		if !ok {
+			continue
+		}
+		n.Label += fmt.Sprintf(" +%d", len(scc)-1)
+		n.Attrs = emphasize(n.Attrs, "peripheries=2")
+		n.Tooltip += "\\ncycle of:"
+		for _, dir := range scc {
+			n.Tooltip += "\\n" + escape(importpath(dir))
+		}
+	}
+	return nil
+}
+
+// cyclesreport lists the packages of each cycle among the module's packages.
+func cyclesreport(w io.Writer, sccs [][]string) {
+	if len(sccs) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "==== CYCLES ====")
+	for _, scc := range sccs {
+		var pkgs []string
+		for _, dir := range scc {
+			pkgs = append(pkgs, importpath(dir))
+		}
+		fmt.Fprintf(w, "%d packages: %s\n", len(pkgs), strings.Join(pkgs, ", "))
+	}
+}
+
+// cyclic is the GraphTransformer that draws the dependencies within each cycle of
+// module packages solid red and thicker.
+type cyclic [][]string
+
+// Transform colors the dependencies whose packages are both members of one cycle.
+func (sccs cyclic) Transform(g *Graph) error {
+	cycle := map[string]int{} // package directory:index of cycle
+	for i, scc := range sccs {
+		for _, dir := range scc {
+			cycle[dir] = i
+		}
+	}
+	for _, e := range g.Edges {
+		if i, ok := cycle[e.From]; ok {
+			if j, ok := cycle[e.To]; ok && i == j {
+				e.Color = "red"
+				e.Attrs = emphasize(e.Attrs, "penwidth=4")
+				e.Tooltip += "\\nin a cycle"
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCycles(t *testing.T) {
+	r := analyze(t, "cycle", Options{})
+	enter(t, r)
+	dir := fixture(t, "cycle")
+	want := [][]string{{filepath.Join(dir, "a"), filepath.Join(dir, "b")}}
+	sccs := cycles(r.lks)
+	if !slices.EqualFunc(sccs, want, slices.Equal[[]string]) {
+		t.Errorf("cycles() = %v, want %v", sccs, want)
+	}
+
+	failOnCycle := flags.failOnCycle
+	defer func() { flags.failOnCycle = failOnCycle }()
+	flags.failOnCycle = true
+	if err := r.a.enforce(r.lks); err == nil {
+		t.Errorf("enforce() with -fail-on-cycle error = nil, want the cycle of a and b")
+	}
+
+	flags.failOnCycle = false
+	if err := newanalyzer().enforce(r.lks); err != nil {
+		t.Errorf("enforce() without -fail-on-cycle error = %v, want nil", err)
+	}
+}
+
+func TestCyclicTransform(t *testing.T) {
+	within, across := &Edge{From: "/a", To: "/b"}, &Edge{From: "/b", To: "/c"}
+	g := &Graph{Edges: []*Edge{within, across}}
+	if err := (cyclic{{"/a", "/b"}}).Transform(g); err != nil {
+		t.Fatal(err)
+	}
+	if within.Color != "red" || within.Attrs != "penwidth=4" {
+		t.Errorf("Transform() edge within the cycle color %q attrs %q, want red penwidth=4", within.Color, within.Attrs)
+	}
+	if across.Color != "" || across.Attrs != "" {
+		t.Errorf("Transform() edge out of the cycle color %q attrs %q, want none", across.Color, across.Attrs)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+// exempt reports whether a package's import path is an -exempt prefix or within it, matching
+// whole path elements, so that example.com/foo exempts example.com/foo/x but not example.com/foobar.
+func exempt(pkg string) bool {
+	for _, prefix := range strings.Split(flags.exempt, ",") {
+		prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+		if prefix != "" && (pkg == prefix || strings.HasPrefix(pkg, prefix+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// violation reports a package failing an enforcement check. Violations
+// within exempt packages are informational and do not fail the run.
+func (a *analyzer) violation(check, pkg string, detail map[string]string) {
+	detail["package"] = pkg
+	msg := gocore.Error(check, errors.New("violation"), detail)
+	if exempt(pkg) {
+		msg.Detail["exempt"] = "true"
+		msg.Info()
+		return
+	}
+	a.violations++
+	msg.Warn()
+}
+
+// enforce runs the enforcement checks requested on the command line.
+func (a *analyzer) enforce(lks linkset) error {
+	if flags.maxFanout > 0 {
+		for pkg, n := range fanouts(lks) {
+			if n > flags.maxFanout {
+				a.violation("max-fanout", importpath(pkg), map[string]string{
+					"fanout": strconv.Itoa(n),
+					"limit":  strconv.Itoa(flags.maxFanout),
+				})
+			}
+		}
+	}
+
+	if flags.maxExports > 0 {
+		for dir, counts := range a.exports {
+			if _, err := gocore.Subdir(dirmod, dir); err != nil {
+				continue
+			}
+			n := 0
+			for _, count := range counts {
+				n += count
+			}
+			if n > flags.maxExports {
+				a.violation("max-exports", importpath(dir), map[string]string{
+					"exports": strconv.Itoa(n),
+					"limit":   strconv.Itoa(flags.maxExports),
+				})
+			}
+		}
+	}
+
+	if flags.noSiblingDeep {
+		for _, lk := range lks.sorted() {
+			if lk.internal() && siblingdeep(lk) {
+				a.violation("no-sibling-deep", importpath(lk.from), map[string]string{
+					"imports": importpath(lk.to),
+				})
+			}
+		}
+	}
+
+	if flags.failOnCycle {
+		for _, scc := range cycles(lks) {
+			var pkgs []string
+			for _, dir := range scc {
+				pkgs = append(pkgs, importpath(dir))
+			}
+			a.violation("fail-on-cycle", pkgs[0], map[string]string{
+				"cycle": strings.Join(pkgs, ","),
+			})
+		}
+	}
+
+	if a.violations > 0 {
+		return gocore.Error("enforce", errors.New("enforcement checks failed"), map[string]string{
+			"violations": strconv.Itoa(a.violations),
+		})
+	}
+	return nil
+}
+
+// fanouts counts the distinct packages on which each module package depends.
+func fanouts(lks linkset) map[string]int {
+	counts := map[string]int{}
+	for lk := range lks {
+		if lk.from == lk.to {
+			continue
+		}
+		if _, err := gocore.Subdir(dirmod, lk.from); err == nil {
+			counts[lk.from]++
+		}
+	}
+	return counts
+}
+
+// fanins counts the distinct module packages that depend on each package.
+func fanins(lks linkset) map[string]int {
+	counts := map[string]int{}
+	for lk := range lks {
+		if lk.from == lk.to {
+			continue
+		}
+		if _, err := gocore.Subdir(dirmod, lk.from); err == nil {
+			counts[lk.to]++
+		}
+	}
+	return counts
+}
+
+// siblingdeep reports whether a module-internal dependency reaches into a descendant of a
+// sibling directory of the referencing package, e.g. a/b importing a/c/d, rather than
+// importing the sibling, a/c, itself.
+func siblingdeep(lk link) bool {
+	from, _ := gocore.Subdir(dirmod, lk.from)
+	to, _ := gocore.Subdir(dirmod, lk.to)
+	from, to = filepath.ToSlash(from), filepath.ToSlash(to)
+	if from == "." {
+		return false // the module's root package has no siblings
+	}
+	parent := path.Dir(from)
+	rel := to
+	if parent != "." {
+		var ok bool
+		if rel, ok = strings.CutPrefix(to, parent+"/"); !ok {
+			return false // not within the parent's subtree
+		}
+	}
+	sibling, deeper, ok := strings.Cut(rel, "/")
+	return ok && deeper != "" && sibling != path.Base(from)
+}
@@ -0,0 +1,57 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"testing"
+)
+
+func TestEnforceUnfiltered(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		setup func()
+	}{
+		{"reduce", func() { flags.reduce = true }},
+		{"root-at", func() { flags.rootAt = "example.com/graph/c" }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := run(t, "graph", func() {
+				flags.format = "dot"
+				flags.maxFanout = 2 // a depends on fmt, b, and c, though b implies c
+				test.setup()
+			})
+			if err == nil {
+				t.Errorf("Main() with -max-fanout 2 error = nil, want the fanout of a")
+			}
+		})
+	}
+
+	if _, _, err := run(t, "graph", func() { flags.format = "dot"; flags.maxFanout = 3 }); err != nil {
+		t.Errorf("Main() with -max-fanout 3 error = %v, want nil", err)
+	}
+}
+
+func TestExempt(t *testing.T) {
+	saved := flags.exempt
+	defer func() { flags.exempt = saved }()
+
+	flags.exempt = "example.com/foo, example.com/bar/"
+	for _, test := range []struct {
+		pkg    string
+		exempt bool
+	}{
+		{"example.com/foo", true},
+		{"example.com/foo/x", true},
+		{"example.com/foobar", false},
+		{"example.com/bar", true},
+		{"example.com/bar/x", true},
+		{"example.com/barn", false},
+		{"example.com", false},
+	} {
+		t.Run(test.pkg, func(t *testing.T) {
+			if exempt(test.pkg) != test.exempt {
+				t.Errorf("exempt(%s) = %t, want %t", test.pkg, !test.exempt, test.exempt)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNoModule reports that the directory analyzed is not within a Go module.
+	ErrNoModule = errors.New("module unresolved")
+
+	// ErrDotMissing reports that the Graphviz dot command to render the graph is not installed.
+	ErrDotMissing = errors.New("graphviz dot command not found")
+
+	// ErrLoadFailed reports that the packages of the module could not be loaded for analysis.
+	ErrLoadFailed = errors.New("package load failed")
+
+	// ErrUnknownCluster reports that a GraphTransformer placed a Node in a cluster that the Graph lacks.
+	ErrUnknownCluster = errors.New("unknown cluster")
+)
@@ -0,0 +1,33 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/zosmac/gocore"
+)
+
+// explain reports for each of the module's files that has a build constraint
+// whether the file is parsed, and the constraint that decided it.
+func (a *analyzer) explain() {
+	var pths []string
+	for pth, b := range a.builds {
+		if _, err := gocore.Subdir(dirmod, pth); err == nil && b.constraint != "" {
+			pths = append(pths, pth)
+		}
+	}
+	sort.Strings(pths)
+
+	fmt.Fprintln(os.Stderr, "==== BUILD CONSTRAINTS ====")
+	for _, pth := range pths {
+		decision := "keep"
+		if !a.builds[pth].keep {
+			decision = "drop"
+		}
+		rel, _ := gocore.Subdir(dirmod, pth)
+		fmt.Fprintf(os.Stderr, "%s  %-40s %s\n", decision, rel, a.builds[pth].constraint)
+	}
+}
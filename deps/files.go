@@ -0,0 +1,37 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/zosmac/gocore"
+)
+
+// filesreport lists for each module package the count and names of the files that contribute to it.
+func (a *analyzer) filesreport() {
+	var dirs []string
+	for dir := range a.files {
+		if _, err := gocore.Subdir(dirmod, dir); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+
+	fmt.Fprintln(os.Stderr, "==== FILES ====")
+	fmt.Fprintf(os.Stderr, "%5s  %s\n", "FILES", "PACKAGE")
+	for _, dir := range dirs {
+		var names []string
+		for pth := range a.files[dir] {
+			names = append(names, path.Base(pth))
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "%5d  %s\n", len(names), importpath(dir))
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "%5s  \t%s\n", "", name)
+		}
+	}
+}
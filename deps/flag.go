@@ -0,0 +1,751 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"errors"
+	"flag"
+	"go/build"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// options defines the godep command line flags.
+	options struct {
+		timeout           time.Duration
+		timeoutPerPackage time.Duration
+		reduce            bool
+		json              bool
+		jsonPretty        bool
+		jsonCompact       bool
+		exempt            string
+		maxFanout         int
+		maxExports        int
+		fromGoList        bool
+		similar           float64
+		metrics           bool
+		changed           string
+		origins           bool
+		format            string
+		nodeMinWidth      float64
+		nodeMaxWidth      float64
+		cgo               bool
+		colors            string
+		qualify           string
+		stdReport         bool
+		unusedImports     bool
+		statsPanel        bool
+		compareModules    string
+		sort              string
+		explainBuild      bool
+		moduleGraph       bool
+		minRefs           int
+		commands          bool
+		summaryJSON       bool
+		rootAt            string
+		depsOnly          bool
+		depsOnlyStd       bool
+		versions          bool
+		manifests         string
+		showDotWarnings   bool
+		reportDepth       int
+		platforms         string
+		unreferenced      bool
+		collapsible       bool
+		files             bool
+		rev               bool
+		owners            string
+		output            string
+		coverage          string
+		modulesList       bool
+		noSiblingDeep     bool
+		goos              string
+		goarch            string
+		tags              string
+		condense          bool
+		failOnCycle       bool
+		zip               string
+		intIDs            bool
+		noCycleHighlight  bool
+		depth             int
+		edgeColorBy       string
+		since             string
+		skip              string
+		implementsMatrix  bool
+		semverImpact      string
+		tests             bool
+		colorMap          string
+		positions         bool
+		strict            bool
+		jobs              int
+		maxLabel          int
+		similarInterfaces float64
+		vendor            string
+		legend            bool
+		focus             string
+		focusDepth        int
+		noStd             bool
+		only              string
+		withTrees         bool
+	}
+)
+
+var (
+	// flags holds the values of the godep command line flags.
+	flags = options{
+		format:       "svg",
+		nodeMinWidth: 1.5,
+		cgo:          build.Default.CgoEnabled, // reflects CGO_ENABLED
+		goos:         build.Default.GOOS,       // reflects GOOS
+		goarch:       build.Default.GOARCH,     // reflects GOARCH
+		colors:       "hash",
+		qualify:      "name",
+		sort:         "name",
+		edgeColorBy:  "gradient",
+		jobs:         runtime.GOMAXPROCS(0),
+		vendor:       "show",
+		focusDepth:   1,
+	}
+
+	// formats are the valid output formats; all but dot, mermaid, and graphml are rendered by Graphviz.
+	formats = gocore.ValidValue[string]{}.Define("svg", "png", "pdf", "xdot", "dot", "report-html", "mermaid", "graphml")
+
+	// extensions map the extensions of -o file names to the formats that they imply.
+	extensions = map[string]string{
+		".svg":     "svg",
+		".png":     "png",
+		".pdf":     "pdf",
+		".xdot":    "xdot",
+		".dot":     "dot",
+		".gv":      "dot",
+		".html":    "report-html",
+		".mmd":     "mermaid",
+		".graphml": "graphml",
+	}
+
+	// colorings are the valid methods for assigning node colors.
+	colorings = gocore.ValidValue[string]{}.Define("hash", "graph")
+
+	// edgecolorings are the valid methods for assigning edge colors.
+	edgecolorings = gocore.ValidValue[string]{}.Define("gradient", "source-cluster", "target-cluster")
+
+	// qualifiers are the valid qualifiers of the identifiers in the trees.
+	qualifiers = gocore.ValidValue[string]{}.Define("name", "path")
+
+	// sortings are the valid orderings of the report's IMPORTS and REFERENCES trees.
+	sortings = gocore.ValidValue[string]{}.Define("name", "fanin", "fanout")
+
+	// vendorings are the valid renderings of vendored packages.
+	vendorings = gocore.ValidValue[string]{}.Define("show", "collapse", "hide")
+)
+
+// init initializes the command line flags.
+func init() {
+	gocore.Flags.CommandDescription = `The godep command produces a Go package dependency graph for the current module.`
+
+	gocore.Flags.Var(
+		&flags.timeout,
+		"timeout",
+		"[-timeout duration]",
+		"Bound the analysis to `duration`; on expiry, render the partial graph collected so far",
+	)
+
+	gocore.Flags.Var(
+		&flags.reduce,
+		"reduce",
+		"[-reduce]",
+		"Render the transitive reduction of the module's internal package dependencies",
+	)
+
+	gocore.Flags.Var(
+		&flags.json,
+		"json",
+		"[-json]",
+		"Write the dependency trees as JSON rather than rendering the graph",
+	)
+
+	gocore.Flags.Var(
+		&flags.jsonPretty,
+		"json-pretty",
+		"[-json-pretty]",
+		"Indent the JSON output (default when writing to a terminal)",
+	)
+
+	gocore.Flags.Var(
+		&flags.jsonCompact,
+		"json-compact",
+		"[-json-compact]",
+		"Write the JSON output on a single line (default when not writing to a terminal)",
+	)
+
+	gocore.Flags.Var(
+		&flags.positions,
+		"positions",
+		"[-positions]",
+		"Record the file:line:column positions of the identifiers, extending the -json DEFINES and REFERENCES trees to them and listing the positions of the references on the edge tooltips",
+	)
+
+	gocore.Flags.Var(
+		&flags.exempt,
+		"exempt",
+		"[-exempt prefix[,prefix...]]",
+		"Comma separated import path `prefixes` of packages whose enforcement check violations are only informational",
+	)
+
+	gocore.Flags.Var(
+		&flags.maxFanout,
+		"max-fanout",
+		"[-max-fanout n]",
+		"Fail if a module package depends on more than `n` packages",
+	)
+
+	gocore.Flags.Var(
+		&flags.fromGoList,
+		"from-go-list",
+		"[-from-go-list]",
+		"Build the package graph from `go list -deps -json ./...` output read from standard input rather than parsing",
+	)
+
+	gocore.Flags.Var(
+		&flags.similar,
+		"similar",
+		"[-similar threshold]",
+		"Report module packages whose exported APIs have a Jaccard similarity of at least `threshold` (0-1]",
+	)
+
+	gocore.Flags.Var(
+		&flags.similarInterfaces,
+		"similar-interfaces",
+		"[-similar-interfaces threshold]",
+		"Report interfaces of different module packages whose method names have a Jaccard similarity of at least `threshold` (0-1], as candidates for consolidation",
+	)
+
+	gocore.Flags.Var(
+		&flags.metrics,
+		"metrics",
+		"[-metrics]",
+		"Report measures of the module's packages, such as internal cohesion",
+	)
+
+	gocore.Flags.Var(
+		&flags.changed,
+		"changed",
+		"[-changed revision]",
+		"Render only the packages changed since the git `revision`, highlighted, with their dependencies and dependents",
+	)
+
+	gocore.Flags.Var(
+		&flags.origins,
+		"origins",
+		"[-origins]",
+		"Show in each edge's tooltip the first source position where the dependency originates",
+	)
+
+	gocore.Flags.Var(
+		&flags.format,
+		"format",
+		"[-format "+strings.Join(formats.ValidValues(), "|")+"]",
+		"Output `format` written to standard output: a Graphviz rendering (xdot adds the layout coordinates to the source), dot for the Graphviz source, report-html for the SVG rendering with the report, mermaid for a Mermaid flowchart, or graphml for GraphML, e.g. for Gephi",
+	)
+
+	gocore.Flags.Var(
+		&flags.nodeMinWidth,
+		"node-min-width",
+		"[-node-min-width inches]",
+		"Minimum width of a node in `inches`",
+	)
+
+	gocore.Flags.Var(
+		&flags.nodeMaxWidth,
+		"node-max-width",
+		"[-node-max-width inches]",
+		"Maximum width of a node in `inches`; if set, each node is sized to its label within the bounds",
+	)
+
+	gocore.Flags.Var(
+		&flags.maxLabel,
+		"max-label",
+		"[-max-label n]",
+		"Truncate each line of a node's label to `n` characters with an ellipsis; the tooltip retains the full path",
+	)
+
+	gocore.Flags.Var(
+		&flags.cgo,
+		"cgo",
+		"[-cgo=true|false]",
+		"Evaluate build constraints with cgo enabled (default from CGO_ENABLED)",
+	)
+
+	gocore.Flags.Var(
+		&flags.tests,
+		"tests",
+		"[-tests]",
+		"Include the _test.go files and _test packages, drawing the dependencies of only the tests dashed",
+	)
+
+	gocore.Flags.Var(
+		&flags.goos,
+		"goos",
+		"[-goos os]",
+		"Evaluate build constraints for the operating system `os` (default from GOOS)",
+	)
+
+	gocore.Flags.Var(
+		&flags.goarch,
+		"goarch",
+		"[-goarch arch]",
+		"Evaluate build constraints for the architecture `arch` (default from GOARCH)",
+	)
+
+	gocore.Flags.Var(
+		&flags.tags,
+		"tags",
+		"[-tags tag,...]",
+		"Evaluate build constraints with the comma separated build `tags` satisfied, e.g. integration,debug",
+	)
+
+	gocore.Flags.Var(
+		&flags.colors,
+		"colors",
+		"[-colors "+strings.Join(colorings.ValidValues(), "|")+"]",
+		"Assign node colors by `method`: hash of the package path, or graph coloring to distinguish adjacent nodes",
+	)
+
+	gocore.Flags.Var(
+		&flags.colorMap,
+		"color-map",
+		"[-color-map file]",
+		"Color the nodes named in the JSON `file` of node names and colors as it maps them, and save the colors of this graph's nodes to it",
+	)
+
+	gocore.Flags.Var(
+		&flags.edgeColorBy,
+		"edge-color-by",
+		"[-edge-color-by "+strings.Join(edgecolorings.ValidValues(), "|")+"]",
+		"Assign edge colors by `method`: gradient of the colors of the nodes, or the color of the top-level subgraph of the referencing or referenced package",
+	)
+
+	gocore.Flags.Var(
+		&flags.timeoutPerPackage,
+		"timeout-per-package",
+		"[-timeout-per-package duration]",
+		"Skip a package directory whose parsing exceeds `duration`, marking its node incomplete",
+	)
+
+	gocore.Flags.Var(
+		&flags.jobs,
+		"jobs",
+		"[-jobs n]",
+		"Parse up to `n` package directories concurrently, by default GOMAXPROCS",
+	)
+
+	gocore.Flags.Var(
+		&flags.qualify,
+		"qualify",
+		"[-qualify "+strings.Join(qualifiers.ValidValues(), "|")+"]",
+		"Qualify the identifiers in the trees with the package `name` or the package's import path",
+	)
+
+	gocore.Flags.Var(
+		&flags.stdReport,
+		"std-report",
+		"[-std-report]",
+		"Report the standard packages the module uses, grouped by category with their usage counts",
+	)
+
+	gocore.Flags.Var(
+		&flags.unusedImports,
+		"unused-imports",
+		"[-unused-imports]",
+		"Report the imports of each module package that are referenced at most once, as candidates for pruning",
+	)
+
+	gocore.Flags.Var(
+		&flags.statsPanel,
+		"stats-panel",
+		"[-stats-panel]",
+		"Render a panel in the corner of the graph totaling its packages, dependencies, and cycles, with the top fan-in package",
+	)
+
+	gocore.Flags.Var(
+		&flags.legend,
+		"legend",
+		"[-legend]",
+		"Add a legend that explains the top-level subgraphs and the directions and colors of the edges",
+	)
+
+	gocore.Flags.Var(
+		&flags.skip,
+		"skip",
+		"[-skip dir,...]",
+		"Ignore the directories with the comma separated base `names`, e.g. generated, in addition to internal and testdata",
+	)
+
+	gocore.Flags.Var(
+		&flags.zip,
+		"zip",
+		"[-zip file]",
+		"Analyze the module of the module zip `file`, as the go tool downloads, extracted to a temporary directory",
+	)
+
+	gocore.Flags.Var(
+		&flags.compareModules,
+		"compare-modules",
+		"[-compare-modules pathA,pathB]",
+		"Write the packages, package dependencies, and exported symbols that differ between the module checkouts at `pathA,pathB`",
+	)
+
+	gocore.Flags.Var(
+		&flags.semverImpact,
+		"semver-impact",
+		"[-semver-impact baseline]",
+		"Recommend the major, minor, or patch version bump of the module's exported API relative to the module checkout at `baseline`",
+	)
+
+	gocore.Flags.Var(
+		&flags.sort,
+		"sort",
+		"[-sort "+strings.Join(sortings.ValidValues(), "|")+"]",
+		"Order the packages of the IMPORTS and REFERENCES reports by `metric`: name, or descending count of dependents or dependencies",
+	)
+
+	gocore.Flags.Var(
+		&flags.explainBuild,
+		"explain-build",
+		"[-explain-build]",
+		"Report for each module file with a build constraint whether it is parsed and the constraint that decided it",
+	)
+
+	gocore.Flags.Var(
+		&flags.moduleGraph,
+		"module-graph",
+		"[-module-graph]",
+		"Render the module requirement graph reported by `go mod graph` rather than the package graph",
+	)
+
+	gocore.Flags.Var(
+		&flags.minRefs,
+		"min-refs",
+		"[-min-refs n]",
+		"Omit from the graph the dependencies of fewer than `n` references to the identifiers of the referenced package",
+	)
+
+	gocore.Flags.Var(
+		&flags.commands,
+		"commands",
+		"[-commands]",
+		"Render the module's main packages, including those sharing a directory with another package, and their dependency closure",
+	)
+
+	gocore.Flags.Var(
+		&flags.summaryJSON,
+		"summary-json",
+		"[-summary-json]",
+		"Write the summary of the module's packages, dependencies, and exported identifiers as JSON rather than rendering the graph",
+	)
+
+	gocore.Flags.Var(
+		&flags.rootAt,
+		"root-at",
+		"[-root-at importpath]",
+		"Render only the package at `importpath`, leftmost, and everything it depends on",
+	)
+
+	gocore.Flags.Var(
+		&flags.focus,
+		"focus",
+		"[-focus package]",
+		"Render only the neighborhood of the `package`, by import path: the package, its dependencies, and its dependents within the -focus-depth",
+	)
+
+	gocore.Flags.Var(
+		&flags.focusDepth,
+		"focus-depth",
+		"[-focus-depth n]",
+		"Extend the -focus neighborhood to the dependencies and dependents within `n` dependencies of the package",
+	)
+
+	gocore.Flags.Var(
+		&flags.noStd,
+		"no-std",
+		"[-no-std]",
+		"Omit the subgraph of the Go standard packages and the dependencies on them, unless the module is the standard library",
+	)
+
+	gocore.Flags.Var(
+		&flags.only,
+		"only",
+		"[-only prefix]",
+		"Render only the packages whose import paths are `prefix` or within it, and the dependencies among them",
+	)
+
+	gocore.Flags.Var(
+		&flags.withTrees,
+		"with-trees",
+		"[-with-trees]",
+		"With -from-go-list, also parse the module for the TYPES, INTERFACES, and other trees and the referenced identifiers of the dependencies",
+	)
+
+	gocore.Flags.Var(
+		&flags.maxExports,
+		"max-exports",
+		"[-max-exports n]",
+		"Fail if a module package exports more than `n` identifiers, counting types, interfaces, functions, methods, and values",
+	)
+
+	gocore.Flags.Var(
+		&flags.strict,
+		"strict",
+		"[-strict]",
+		"Fail if any package's directory fails to parse completely",
+	)
+
+	gocore.Flags.Var(
+		&flags.failOnCycle,
+		"fail-on-cycle",
+		"[-fail-on-cycle]",
+		"Fail if the module's packages depend on one another in a cycle",
+	)
+
+	gocore.Flags.Var(
+		&flags.noSiblingDeep,
+		"no-sibling-deep",
+		"[-no-sibling-deep]",
+		"Fail if a module package imports a descendant of a sibling directory, e.g. a/b importing a/c/d",
+	)
+
+	gocore.Flags.Var(
+		&flags.depsOnly,
+		"deps-only",
+		"[-deps-only]",
+		"Render each imported module as a single node, keeping the module's packages expanded",
+	)
+
+	gocore.Flags.Var(
+		&flags.depsOnlyStd,
+		"deps-only-std",
+		"[-deps-only-std]",
+		"With -deps-only, also render the standard library as a single node",
+	)
+
+	gocore.Flags.Var(
+		&flags.versions,
+		"versions",
+		"[-versions]",
+		"Show in the labels of imported packages the versions of their modules",
+	)
+
+	gocore.Flags.Var(
+		&flags.manifests,
+		"manifests",
+		"[-manifests directory]",
+		"Write to `directory` a pkg.deps.json manifest of the direct dependencies of each module package",
+	)
+
+	gocore.Flags.Var(
+		&flags.showDotWarnings,
+		"show-dot-warnings",
+		"[-show-dot-warnings]",
+		"Forward the warnings of a successful Graphviz dot rendering to standard error",
+	)
+
+	gocore.Flags.Var(
+		&flags.reportDepth,
+		"report-depth",
+		"[-report-depth n]",
+		"Limit the report of the trees to `n` levels, showing ... for each truncated subtree",
+	)
+
+	gocore.Flags.Var(
+		&flags.platforms,
+		"platforms",
+		"[-platforms goos[/goarch][,...]]",
+		"With -json, analyze the module for each of the `platforms` and write the imports and dependencies with the platforms on which each applies",
+	)
+
+	gocore.Flags.Var(
+		&flags.unreferenced,
+		"unreferenced",
+		"[-unreferenced]",
+		"Report the module's packages, other than commands, on which no other module package depends",
+	)
+
+	gocore.Flags.Var(
+		&flags.depth,
+		"depth",
+		"[-depth n]",
+		"Render the packages deeper than `n` directory levels as the package at level n, e.g. a/b/c as a/b with -depth=2 (default 0, unlimited)",
+	)
+
+	gocore.Flags.Var(
+		&flags.intIDs,
+		"int-ids",
+		"[-int-ids]",
+		"Identify graphviz nodes with stable integers from the sorted order of the package paths",
+	)
+
+	gocore.Flags.Var(
+		&flags.collapsible,
+		"collapsible",
+		"[-collapsible]",
+		"Identify the graph's subgraphs so that, in the report-html output, clicking a subgraph collapses or expands it",
+	)
+
+	gocore.Flags.Var(
+		&flags.files,
+		"files",
+		"[-files]",
+		"Report the count and names of the files that contribute to each module package",
+	)
+
+	gocore.Flags.Var(
+		&flags.implementsMatrix,
+		"implements-matrix",
+		"[-implements-matrix]",
+		"Report the module's types and the interfaces that they implement as a matrix",
+	)
+
+	gocore.Flags.Var(
+		&flags.rev,
+		"rev",
+		"[-rev]",
+		"Include in the graph's label the git commit of the module's checkout",
+	)
+
+	gocore.Flags.Var(
+		&flags.noCycleHighlight,
+		"no-cycle-highlight",
+		"[-no-cycle-highlight]",
+		"Draw the dependencies within cycles of module packages in their normal colors rather than red",
+	)
+
+	gocore.Flags.Var(
+		&flags.condense,
+		"condense",
+		"[-condense]",
+		"Render each cycle of module packages as a single node, so that the dependencies between nodes are acyclic",
+	)
+
+	gocore.Flags.Var(
+		&flags.owners,
+		"owners",
+		"[-owners file]",
+		"Render only the dependencies between module packages of different teams, as mapped by the package path prefixes and teams of `file`, colored by team pair",
+	)
+
+	gocore.Flags.Var(
+		&flags.since,
+		"since",
+		"[-since date]",
+		"Highlight the dependencies introduced after `date`, YYYY-MM-DD, according to git blame of their originating references",
+	)
+
+	gocore.Flags.Var(
+		&flags.coverage,
+		"coverage",
+		"[-coverage profile]",
+		"Annotate each module package with its coverage from the Go coverage `profile`, emphasizing poorly covered packages that others depend on",
+	)
+
+	gocore.Flags.Var(
+		&flags.modulesList,
+		"modules-list",
+		"[-modules-list]",
+		"Write the distinct paths of the third-party modules that the module imports, one per line, e.g. for go mod why -m",
+	)
+
+	gocore.Flags.Var(
+		&flags.vendor,
+		"vendor",
+		"[-vendor "+strings.Join(vendorings.ValidValues(), "|")+"]",
+		"Render the vendored packages by `mode`: show each as an import, collapse them onto a single vendor node, or hide them",
+	)
+
+	for _, name := range []string{"o", "output"} {
+		gocore.Flags.Var(
+			&flags.output,
+			name,
+			"[-"+name+" file]",
+			"Write the output to `file` rather than standard output; absent -format, the file's extension implies the format",
+		)
+	}
+}
+
+// validate checks the command line flags before any analysis begins.
+func validate() error {
+	if format, ok := extensions[filepath.Ext(flags.output)]; ok && !set("format") {
+		flags.format = format
+	}
+
+	for _, v := range []struct {
+		name  string
+		value string
+		valid gocore.ValidValue[string]
+	}{
+		{"format", flags.format, formats},
+		{"colors", flags.colors, colorings},
+		{"edge-color-by", flags.edgeColorBy, edgecolorings},
+		{"qualify", flags.qualify, qualifiers},
+		{"sort", flags.sort, sortings},
+		{"vendor", flags.vendor, vendorings},
+	} {
+		if !v.valid.IsValid(v.value) {
+			return gocore.Error(v.name, errors.New("unknown"), map[string]string{
+				v.name:  v.value,
+				"valid": strings.Join(v.valid.ValidValues(), ","),
+			})
+		}
+	}
+
+	if !knownOS[flags.goos] {
+		return gocore.Error("goos", errors.New("unknown"), map[string]string{
+			"goos": flags.goos,
+		})
+	}
+	if !knownArch[flags.goarch] {
+		return gocore.Error("goarch", errors.New("unknown"), map[string]string{
+			"goarch": flags.goarch,
+		})
+	}
+
+	if flags.focusDepth < 1 {
+		return gocore.Error("focus-depth", errors.New("must be at least 1"), map[string]string{
+			"focus-depth": strconv.Itoa(flags.focusDepth),
+		})
+	}
+
+	if flags.jobs < 1 {
+		return gocore.Error("jobs", errors.New("must be at least 1"), map[string]string{
+			"jobs": strconv.Itoa(flags.jobs),
+		})
+	}
+
+	if flags.platforms != "" && !flags.json {
+		return gocore.Error("flags", errors.New("-platforms requires -json"))
+	}
+
+	if flags.withTrees && !flags.fromGoList {
+		return gocore.Error("flags", errors.New("-with-trees requires -from-go-list"))
+	}
+
+	if flags.jsonPretty && flags.jsonCompact {
+		return gocore.Error("flags", errors.New("-json-pretty and -json-compact are mutually exclusive"))
+	}
+
+	return nil
+}
+
+// set reports whether a flag was set on the command line.
+func set(name string) bool {
+	var found bool
+	gocore.Flags.Visit(func(f *flag.Flag) {
+		found = found || f.Name == name
+	})
+	return found
+}
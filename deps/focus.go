@@ -0,0 +1,55 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"slices"
+)
+
+type (
+	// focus is the GraphTransformer that prunes the Graph to the neighborhood of a package:
+	// the package, the packages that it depends on, and the packages that depend on it,
+	// within a radius of dependencies.
+	focus struct {
+		dir    string
+		radius int
+	}
+)
+
+// Transform removes the nodes outside of the neighborhood and the edges of the removed nodes.
+func (f focus) Transform(g *Graph) error {
+	succs, preds := map[string][]string{}, map[string][]string{}
+	for _, e := range g.Edges {
+		if g.Nodes[e.From] != nil && g.Nodes[e.To] != nil {
+			succs[e.From] = append(succs[e.From], e.To)
+			preds[e.To] = append(preds[e.To], e.From)
+		}
+	}
+
+	within := map[string]struct{}{f.dir: {}}
+	for _, adjacent := range []map[string][]string{succs, preds} {
+		ring := []string{f.dir}
+		for range f.radius {
+			var next []string
+			for _, dir := range ring {
+				for _, adj := range adjacent[dir] {
+					if _, ok := within[adj]; !ok {
+						within[adj] = struct{}{}
+						next = append(next, adj)
+					}
+				}
+			}
+			ring = next
+		}
+	}
+
+	for dir := range g.Nodes {
+		if _, ok := within[dir]; !ok {
+			delete(g.Nodes, dir)
+		}
+	}
+	g.Edges = slices.DeleteFunc(g.Edges, func(e *Edge) bool {
+		return g.Nodes[e.From] == nil || g.Nodes[e.To] == nil
+	})
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestFocusTransform(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		radius int
+		nodes  []string
+		edges  []string
+	}{
+		{"leaf", 1, []string{"b", "c", "d"}, []string{"b c", "d c"}},
+		{"leaf within 2", 2, []string{"a", "b", "c", "d"}, []string{"a b", "a d", "b c", "d c"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			g := &Graph{Nodes: map[string]*Node{}}
+			for _, dir := range []string{"a", "b", "c", "d", "e"} {
+				g.Nodes[dir] = &Node{Dir: dir}
+			}
+			for _, e := range [][2]string{{"e", "a"}, {"a", "b"}, {"a", "d"}, {"b", "c"}, {"d", "c"}} {
+				g.Edges = append(g.Edges, &Edge{From: e[0], To: e[1]})
+			}
+
+			if err := (focus{dir: "c", radius: test.radius}).Transform(g); err != nil {
+				t.Fatal(err)
+			}
+
+			var nodes, edges []string
+			for dir := range g.Nodes {
+				nodes = append(nodes, dir)
+			}
+			for _, e := range g.Edges {
+				edges = append(edges, e.From+" "+e.To)
+			}
+			sort.Strings(nodes)
+			sort.Strings(edges)
+			if !slices.Equal(nodes, test.nodes) {
+				t.Errorf("Transform() nodes = %v, want %v", nodes, test.nodes)
+			}
+			if !slices.Equal(edges, test.edges) {
+				t.Errorf("Transform() edges = %v, want %v", edges, test.edges)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+type (
+	// listed is the subset of a `go list -json` package description that godep uses.
+	listed struct {
+		ImportPath string
+		Name       string
+		Dir        string
+		Imports    []string
+		Module     *struct {
+			Main bool
+		}
+	}
+)
+
+// golist builds the IMPORTS tree and the package dependencies from the output of
+// `go list -deps -json ./...`, as resolved by the go tool rather than by parsing.
+func (a *analyzer) golist(r io.Reader) (linkset, error) {
+	var pkgs []listed
+	dirs := map[string]listed{} // import path:package
+	dec := json.NewDecoder(r)
+	for {
+		var pkg listed
+		if err := dec.Decode(&pkg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		pkg.Dir = unversion(pkg.Dir)
+		pkgs = append(pkgs, pkg)
+		dirs[pkg.ImportPath] = pkg
+	}
+
+	lks := linkset{}
+	for _, pkg := range pkgs {
+		for _, pth := range pkg.Imports {
+			imp, ok := dirs[pth]
+			if !ok || imp.Dir == "" { // e.g. the "C" package
+				continue
+			}
+			if flags.qualify == "path" {
+				a.imps.Add(imp.ImportPath, imp.Dir)
+			} else {
+				a.imps.Add(imp.Name, imp.Dir)
+			}
+			if pkg.Module != nil && pkg.Module.Main {
+				lks[link{from: pkg.Dir, to: imp.Dir}] = tree{}
+			}
+		}
+	}
+
+	return lks, nil
+}
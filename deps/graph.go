@@ -0,0 +1,236 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// Graph is the model of the package dependencies that nodegraph serializes
+	// for graphviz. A GraphTransformer may relabel, recolor, filter, or annotate
+	// its nodes and edges before serialization.
+	Graph struct {
+		Nodes map[string]*Node // keyed by package directory
+		Edges []*Edge
+
+		palette map[string]string // node colors that override the hashed colors
+	}
+
+	// Node is a package of the Graph. A GraphTransformer may only place a Node in one of the
+	// Graph's clusters: std, import, the module, or another module of the workspace. The
+	// Tooltip of a Node or an Edge is inserted in the graphviz source as is, so a transformer
+	// escapes the backslashes and double quotes of each line that it appends, preceded by the
+	// escaped newline "\\n".
+	Node struct {
+		Dir     string // package source directory
+		Cluster string // top-level subgraph: std, the module, or import
+		Path    string // package path within its cluster, "." for the module itself
+		Name    string // graphviz node identifier
+		Label   string
+		Color   string
+		Attrs   string // graphviz attributes to emphasize the node
+		Tooltip string // additional lines for the node's tooltip, escaped
+	}
+
+	// Edge is a dependency of the Graph of a referencing package on a referenced package.
+	Edge struct {
+		From    string   // referencing package directory
+		To      string   // referenced package directory
+		Symbols []string // referenced identifiers that justify the dependency
+		Refs    int      // references to the identifiers, at least one
+		Color   string
+		Tooltip string // additional lines for the edge's tooltip, escaped
+		Attrs   string // graphviz attributes to emphasize the edge
+	}
+
+	// GraphTransformer post-processes the Graph before nodegraph serializes it.
+	GraphTransformer interface {
+		Transform(*Graph) error
+	}
+)
+
+var (
+	// transformers are invoked in order of registration to post-process the Graph of each
+	// serialization, before those that the analysis's flags add.
+	transformers []GraphTransformer
+)
+
+// RegisterTransformer adds a GraphTransformer to the pipeline of every serialization.
+func RegisterTransformer(t GraphTransformer) {
+	transformers = append(transformers, t)
+}
+
+// register adds a GraphTransformer to the pipeline of the analysis's serializations, after those registered.
+func (a *analyzer) register(t GraphTransformer) {
+	a.transformers = append(a.transformers, t)
+}
+
+// model assembles the Graph of the package dependencies to render.
+func (a *analyzer) model(lks linkset) *Graph {
+	if flags.colors == "graph" {
+		a.colorize(lks)
+	}
+
+	g := &Graph{Nodes: map[string]*Node{}, palette: a.palette}
+	for _, lk := range lks.sorted() {
+		from, to := g.node(lk.from), g.node(lk.to)
+		if from == nil || to == nil ||
+			from.Name == to.Name || // ignore intra-node calls
+			dirmod != dirstd && !member(from.Cluster) && !member(to.Cluster) || // neither is in module or workspace
+			a.weight(lk, lks[lk]) < flags.minRefs {
+			continue
+		}
+		g.Nodes[from.Dir] = from
+		g.Nodes[to.Dir] = to
+
+		e := &Edge{
+			From:  lk.from,
+			To:    lk.to,
+			Refs:  a.weight(lk, lks[lk]),
+			Color: from.Color + ";0.5:" + to.Color,
+		}
+		switch flags.edgeColorBy {
+		case "source-cluster":
+			e.Color = color(from.Cluster)
+		case "target-cluster":
+			e.Color = color(to.Cluster)
+		}
+		for sym := range lks[lk] {
+			e.Symbols = append(e.Symbols, sym)
+		}
+		sort.Strings(e.Symbols)
+		if flags.origins {
+			e.Tooltip = a.origin(lk, lks[lk])
+		}
+		if flags.positions {
+			e.Tooltip += a.sites(lk, lks[lk])
+		}
+		e.Tooltip += a.satisfactions(lk)
+		if flags.tests && a.testonly(lk, lks[lk]) {
+			e.Attrs = emphasize(e.Attrs, "style=dashed")
+			e.Tooltip += "\\ntests only"
+		}
+		g.Edges = append(g.Edges, e)
+	}
+
+	for dir := range a.doconly { // documentation only packages have no dependencies to place them
+		if _, err := gocore.Subdir(dirmod, dir); err != nil || dirmod == dirstd {
+			continue
+		}
+		if n := g.node(dir); n != nil {
+			n.Label += "\n(doc only)"
+			n.Tooltip += "\\ndocumentation only"
+			g.Nodes[dir] = n
+		}
+	}
+
+	for dir, attrs := range a.highlights {
+		if n := g.node(dir); n != nil {
+			n.Attrs = emphasize(n.Attrs, attrs)
+			g.Nodes[dir] = n
+		}
+	}
+
+	return g
+}
+
+// node creates the Node for a package directory, or returns nil if the directory
+// is not a package of std, the module, or imports.
+func (g *Graph) node(dir string) *Node {
+	if n, ok := g.Nodes[dir]; ok {
+		return n
+	}
+	tg, pkg, ok := identify(dir)
+	if !ok {
+		return nil
+	}
+	n := &Node{
+		Dir:     dir,
+		Cluster: tg,
+		Path:    pkg,
+		Name:    nodename(dir),
+		Label:   pkg,
+	}
+	if pkg == "." {
+		n.Label = tg // package = module
+	}
+	if flags.versions && tg == imports {
+		if v := version(dir); v != "" {
+			n.Label += "@" + v
+		}
+	}
+	n.Color = color(n.Name)
+	if c, ok := g.palette[n.Name]; ok {
+		n.Color = c
+	}
+	return n
+}
+
+// emphasize appends graphviz attributes to those of a node or edge, so that the emphases of
+// the model and of each GraphTransformer accumulate.
+func emphasize(attrs, more string) string {
+	return strings.TrimSpace(attrs + " " + more)
+}
+
+// transform invokes the registered GraphTransformers, and then the analysis's, on the Graph.
+func (a *analyzer) transform(g *Graph) error {
+	for _, t := range slices.Concat(transformers, a.transformers) {
+		if err := t.Transform(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// weight counts the references of a dependency to its symbols. The count is at least that of
+// the symbols, as the dependencies that -condense merges count the references of the first
+// package of a cycle, and at least one, as the dependencies from go list have no symbols.
+func (a *analyzer) weight(lk link, syms tree) int {
+	var refs int
+	for sym := range syms {
+		refs += a.counts[lk.from+" "+sym]
+	}
+	return max(refs, len(syms), 1)
+}
+
+// testonly reports whether only test files reference the symbols of a dependency.
+func (a *analyzer) testonly(lk link, syms tree) bool {
+	for sym := range syms {
+		if _, ok := a.production[lk.from][sym]; ok {
+			return false
+		}
+	}
+	return len(syms) > 0
+}
+
+// satisfactions lists for a dependency the types of the referencing package that implement
+// interfaces of the defining package, with the methods that satisfy each interface.
+func (a *analyzer) satisfactions(lk link) string {
+	var lines []string
+	for ifc, typs := range a.sets {
+		if _, ok := a.defs[ifc][lk.to]; !ok {
+			continue
+		}
+		for typ, mths := range typs {
+			if _, ok := a.defs[typ][lk.from]; !ok || len(mths) == 0 {
+				continue
+			}
+			var sigs []string
+			for mth := range mths {
+				sigs = append(sigs, mth)
+			}
+			sort.Strings(sigs)
+			lines = append(lines, escape(typ+" implements "+ifc+": "+strings.Join(sigs, ", ")))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	sort.Strings(lines)
+	return "\\n" + strings.Join(lines, "\\n")
+}
@@ -0,0 +1,35 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestModelDocOnly(t *testing.T) {
+	g := graphviz(t, analyze(t, "doconly", Options{}))
+	_, node, _ := strings.Cut(g, `"example.com/doconly: docs" [`)
+	if node, _, _ = strings.Cut(node, "\n"); !strings.Contains(node, `label="docs\n(doc only)"`) {
+		t.Errorf("Graphviz() lacks the documentation only node of docs:\n%s", g)
+	}
+}
+
+// recluster is a GraphTransformer that moves the nodes to a cluster.
+type recluster string
+
+func (cluster recluster) Transform(g *Graph) error {
+	for _, n := range g.Nodes {
+		n.Cluster = string(cluster)
+	}
+	return nil
+}
+
+func TestNodegraphUnknownCluster(t *testing.T) {
+	r := analyze(t, "graph", Options{})
+	r.a.register(recluster("nowhere"))
+	if _, err := r.Graphviz(); !errors.Is(err, ErrUnknownCluster) || !strings.Contains(err.Error(), "nowhere") {
+		t.Errorf("Graphviz() error = %v, want %v naming the cluster nowhere", err, ErrUnknownCluster)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// graphml is the root element of a GraphML document.
+	graphml struct {
+		XMLName xml.Name     `xml:"graphml"`
+		Xmlns   string       `xml:"xmlns,attr"`
+		Keys    []graphmlKey `xml:"key"`
+		Graph   graphmlGraph `xml:"graph"`
+	}
+
+	// graphmlKey declares an attribute of the nodes or edges.
+	graphmlKey struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+
+	// graphmlGraph contains the nodes and edges.
+	graphmlGraph struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphmlNode `xml:"node"`
+		Edges       []graphmlEdge `xml:"edge"`
+	}
+
+	// graphmlNode is a package.
+	graphmlNode struct {
+		ID   string        `xml:"id,attr"`
+		Data []graphmlData `xml:"data"`
+	}
+
+	// graphmlEdge is a dependency of the referencing package, the source, on the referenced package, the target.
+	graphmlEdge struct {
+		Source string        `xml:"source,attr"`
+		Target string        `xml:"target,attr"`
+		Data   []graphmlData `xml:"data"`
+	}
+
+	// graphmlData is the value of an attribute of a node or edge.
+	graphmlData struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+)
+
+// graphML produces the package connections as a GraphML document, e.g. for Gephi or yEd.
+// Each node is identified by its graphviz node name with attributes for its label, its
+// top-level subgraph, its package path, and its directory, so that the nodes may be colored
+// by cluster. Each edge is directed from the referencing to the referenced package.
+func graphML(g *Graph) ([]byte, error) {
+	doc := graphml{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "cluster", For: "node", AttrName: "cluster", AttrType: "string"},
+			{ID: "path", For: "node", AttrName: "path", AttrType: "string"},
+			{ID: "dir", For: "node", AttrName: "dir", AttrType: "string"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+			{ID: "symbols", For: "edge", AttrName: "symbols", AttrType: "string"},
+		},
+		Graph: graphmlGraph{
+			ID:          gomod,
+			EdgeDefault: "directed",
+		},
+	}
+
+	var dirs []string
+	for dir := range g.Nodes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		n := g.Nodes[dir]
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{
+			ID: n.Name,
+			Data: []graphmlData{
+				{Key: "label", Value: n.Label},
+				{Key: "cluster", Value: n.Cluster},
+				{Key: "path", Value: n.Path},
+				{Key: "dir", Value: n.Dir},
+			},
+		})
+	}
+
+	for _, e := range g.Edges {
+		rn, dn := g.Nodes[e.From], g.Nodes[e.To]
+		if rn == nil || dn == nil { // removed by a transformer
+			continue
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			Source: rn.Name,
+			Target: dn.Name,
+			Data: []graphmlData{
+				{Key: "weight", Value: strconv.Itoa(e.Refs)},
+				{Key: "symbols", Value: strings.Join(e.Symbols, " ")},
+			},
+		})
+	}
+	sort.Slice(doc.Graph.Edges, func(i, j int) bool {
+		ei, ej := doc.Graph.Edges[i], doc.Graph.Edges[j]
+		return ei.Source < ej.Source || ei.Source == ej.Source && ei.Target < ej.Target
+	})
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
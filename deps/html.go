@@ -0,0 +1,68 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// collapser is the script that collapses a subgraph, hiding its nodes and their edges, or expands it on click.
+const collapser = `
+<script>
+document.querySelectorAll("g.collapsible").forEach(function (cluster) {
+	cluster.style.cursor = "pointer";
+	cluster.addEventListener("click", function () {
+		var collapsed = cluster.classList.toggle("collapsed");
+		document.querySelectorAll("g.in-" + cluster.id).forEach(function (g) {
+			g.style.display = collapsed ? "none" : "";
+		});
+	});
+});
+</script>`
+
+// reporthtml assembles a self-contained HTML document of the SVG rendering
+// of the nodegraph followed by the trees of the report in collapsible sections.
+func (a *analyzer) reporthtml(svg []byte) []byte {
+	if i := bytes.Index(svg, []byte("<svg")); i >= 0 {
+		svg = svg[i:] // drop the xml prolog to embed inline
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Module %[1]s Packages</title>
+<style>
+body { background-color: black; color: lightgrey; font-family: sans-serif; }
+summary { cursor: pointer; font-weight: bold; margin-top: 0.5em; }
+pre { tab-size: 4; }
+</style>
+</head>
+<body>
+<h1>Module %[1]s Packages</h1>
+`,
+		html.EscapeString(gomod),
+	)
+	buf.Write(svg)
+
+	for t := range TREES {
+		text := &bytes.Buffer{}
+		tbl, order := a.arrangement(t)
+		a.trees[t].Traverse(0, tbl, order, display(text))
+		fmt.Fprintf(buf, "\n<details>\n<summary>%s</summary>\n<pre>%s</pre>\n</details>",
+			names[t],
+			html.EscapeString(text.String()),
+		)
+	}
+
+	if flags.collapsible {
+		buf.WriteString(collapser)
+	}
+
+	buf.WriteString("\n</body>\n</html>\n")
+
+	return buf.Bytes()
+}
@@ -2,7 +2,7 @@
 
 //go:build ignore
 
-package main
+package deps
 
 import (
 	"errors"
@@ -23,10 +23,8 @@ func (imp *importr) Import(pth string) (*types.Package, error) {
 }
 
 func (imp *importr) ImportFrom(pth, from string, mode types.ImportMode) (*types.Package, error) {
-	for skip := range skipdirs {
-		if strings.Contains(pth, skip) {
-			return nil, fmt.Errorf("skip import of package %s with %s in path", pth, skip)
-		}
+	if skip, ok := skipped(pth); ok {
+		return nil, fmt.Errorf("skip import of package %s with %s in path", pth, skip)
 	}
 
 	// determine local directory path from import path
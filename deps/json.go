@@ -0,0 +1,71 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/zosmac/gocore"
+)
+
+// encode writes the trees as a JSON object keyed by tree name, and with -metrics,
+// the measures of the module packages keyed by METRICS. With -positions, the DEFINES
+// and REFERENCES trees extend to the source positions of the identifiers. If the type checker
+// did not decide the IMPLEMENTS tree, e.g. for a -zip, TYPECHECKED is false.
+func (a *analyzer) encode(w io.Writer, lks linkset) error {
+	obj := map[string]any{}
+	for t, name := range names {
+		obj[name] = a.trees[t]
+	}
+	if !a.typechecked {
+		obj["TYPECHECKED"] = false // IMPLEMENTS compares the method signatures
+	}
+	if flags.metrics {
+		obj["METRICS"] = a.couplings(lks)
+	}
+	if flags.positions {
+		defs := tree{} // identifier:directory:positions
+		for def, dirs := range a.trees[DEFINES] {
+			for dir := range dirs {
+				defs.Add(def, dir)
+				for pos := range a.positions[DEFINES][def][dir] {
+					defs.Add(def, dir, pos)
+				}
+			}
+		}
+		obj[names[DEFINES]] = defs
+
+		refs := tree{} // identifier:referencing directory:defining directory:positions
+		for ref, rdirs := range a.trees[REFERENCES] {
+			for rdir, ddirs := range rdirs {
+				for ddir := range ddirs {
+					refs.Add(ref, rdir, ddir)
+					for pos := range a.positions[REFERENCES][ref][rdir] {
+						refs.Add(ref, rdir, ddir, pos)
+					}
+				}
+			}
+		}
+		obj[names[REFERENCES]] = refs
+	}
+
+	return encoder(w).Encode(obj) // encoding/json sorts map keys, so output is stable
+}
+
+// encoder creates a JSON encoder whose output is indented when writing
+// to a terminal unless overridden by -json-compact or -json-pretty.
+func encoder(w io.Writer) *json.Encoder {
+	pretty := gocore.IsTerminal(stdout)
+	if flags.jsonPretty {
+		pretty = true
+	} else if flags.jsonCompact {
+		pretty = false
+	}
+
+	enc := json.NewEncoder(w)
+	if pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc
+}
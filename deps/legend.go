@@ -0,0 +1,44 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"html"
+)
+
+// legend formats a graphviz cluster with an HTML-like table label that explains the top-level
+// subgraphs, the directions of the edges, and their colors. The cluster is ranked last to
+// place it right of the imports, clear of the ranks of the packages.
+func (l *layout) legend() string {
+	row := `<tr><td align="left">%s</td><td align="left">%s</td></tr>`
+	var rows string
+	if _, ok := l.graphmap[standard]; ok {
+		rows += fmt.Sprintf(row, "Go Standard Packages", "the packages of the Go standard library")
+	}
+	if dirmod != dirstd {
+		rows += fmt.Sprintf(row, html.EscapeString(gomod), "the packages of the module")
+	}
+	for _, mod := range workspaces() {
+		rows += fmt.Sprintf(row, html.EscapeString(mod), "the packages of another module of the workspace")
+	}
+	rows += fmt.Sprintf(row, "Imported/Vendored Packages", "the packages of the module's requirements, or vendored")
+	rows += fmt.Sprintf(row, "back edge", "a dependency on a package of a subgraph to the left, or of the same subgraph: the arrow points to the referenced package")
+	rows += fmt.Sprintf(row, "forward edge", "a dependency on a package of a subgraph to the right: the arrow points left, to the referencing package")
+
+	switch flags.edgeColorBy {
+	case "gradient":
+		rows += fmt.Sprintf(row, "gradient", "the edge's colors are those of the referencing and referenced packages' nodes")
+	case "source-cluster":
+		rows += fmt.Sprintf(row, "color", "the edge's color is that of the referencing package's top-level subgraph")
+	case "target-cluster":
+		rows += fmt.Sprintf(row, "color", "the edge's color is that of the referenced package's top-level subgraph")
+	}
+
+	return `
+subgraph "cluster_legend" { label="Legend" fontcolor=lightgrey color=lightgrey
+"Legend" [shape=plaintext style="" fontcolor=lightgrey label=<<table border="0" cellborder="0">` + rows + `</table>>]
+}
+{ rank=max "Legend" }
+`
+}
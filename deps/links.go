@@ -0,0 +1,268 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// link identifies the dependency of a referencing package directory on a defining package directory.
+	link struct {
+		from string
+		to   string
+	}
+
+	// linkset maps each dependency to the symbols that justify it.
+	linkset map[link]tree
+)
+
+// links collects the package dependencies recorded in the references tree.
+func links(references tree) linkset {
+	lks := linkset{}
+	for ref, refs := range references {
+		for rabs, defs := range refs {
+			for dabs := range defs {
+				lk := link{from: rabs, to: dabs}
+				if _, ok := lks[lk]; !ok {
+					lks[lk] = tree{}
+				}
+				lks[lk].Add(ref)
+			}
+		}
+	}
+	return lks
+}
+
+// importpath converts a package directory to its import path.
+func importpath(abs string) string {
+	if _, a, ok := strings.Cut(abs, "/vendor/"); ok {
+		return a
+	}
+	if pth, ok := workspacepath(abs); ok {
+		return pth
+	}
+	if rel, err := gocore.Subdir(dirmod, abs); err == nil && dirmod != dirstd {
+		return path.Join(gomod, filepath.ToSlash(rel))
+	}
+	for _, dir := range []string{dirstd, dirimps} {
+		if rel, err := gocore.Subdir(dir, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return abs
+}
+
+// sorted returns the dependencies ordered by referencing and then defining package.
+func (lks linkset) sorted() []link {
+	sorted := make([]link, 0, len(lks))
+	for lk := range lks {
+		sorted = append(sorted, lk)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].from < sorted[j].from ||
+			sorted[i].from == sorted[j].from && sorted[i].to < sorted[j].to
+	})
+	return sorted
+}
+
+// internal reports whether both ends of a dependency are module packages.
+func (lk link) internal() bool {
+	_, errf := gocore.Subdir(dirmod, lk.from)
+	_, errt := gocore.Subdir(dirmod, lk.to)
+	return errf == nil && errt == nil
+}
+
+// reduce removes module-internal dependencies implied by transitivity, preserving reachability.
+// Each dependency is evaluated against the already reduced set so that dependencies that are
+// redundant because of one another in a cycle are not all removed.
+func (lks linkset) reduce() {
+	succs := map[string]map[string]struct{}{}
+	for lk := range lks {
+		if lk.from == lk.to || !lk.internal() {
+			continue
+		}
+		if _, ok := succs[lk.from]; !ok {
+			succs[lk.from] = map[string]struct{}{}
+		}
+		succs[lk.from][lk.to] = struct{}{}
+	}
+
+	for _, lk := range lks.sorted() {
+		if _, ok := succs[lk.from][lk.to]; !ok {
+			continue
+		}
+		delete(succs[lk.from], lk.to)
+		if reachable(succs, lk.from, lk.to) {
+			delete(lks, lk) // redundant
+		} else {
+			succs[lk.from][lk.to] = struct{}{} // essential
+		}
+	}
+}
+
+// reachable reports whether a path leads from one package to another.
+func reachable(succs map[string]map[string]struct{}, from, to string) bool {
+	visited := map[string]struct{}{from: {}}
+	stack := []string{from}
+	for len(stack) > 0 {
+		pkg := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for succ := range succs[pkg] {
+			if succ == to {
+				return true
+			}
+			if _, ok := visited[succ]; !ok {
+				visited[succ] = struct{}{}
+				stack = append(stack, succ)
+			}
+		}
+	}
+	return false
+}
+
+// closure reduces the dependencies to those of the root packages and everything that the roots depend on.
+func (lks linkset) closure(roots []string) {
+	succs := map[string][]string{}
+	for lk := range lks {
+		succs[lk.from] = append(succs[lk.from], lk.to)
+	}
+
+	var stack []string
+	reached := map[string]struct{}{}
+	for _, dir := range roots {
+		reached[dir] = struct{}{}
+		stack = append(stack, dir)
+	}
+	for len(stack) > 0 {
+		dir := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range succs[dir] {
+			if _, ok := reached[next]; !ok {
+				reached[next] = struct{}{}
+				stack = append(stack, next)
+			}
+		}
+	}
+
+	for lk := range lks {
+		if _, ok := reached[lk.from]; !ok {
+			delete(lks, lk)
+		}
+	}
+}
+
+// resolve finds the directory of the package of the dependencies with an import path.
+func (lks linkset) resolve(pth string) (string, bool) {
+	for lk := range lks {
+		for _, dir := range []string{lk.from, lk.to} {
+			if importpath(dir) == pth {
+				return dir, true
+			}
+		}
+	}
+	return "", false
+}
+
+// collapse merges the dependencies on the packages of each imported module, and if
+// -deps-only-std of the standard library, onto a single node for the module.
+func (lks linkset) collapse() linkset {
+	roots := map[string]string{} // package directory:module directory
+	root := func(dir string) string {
+		if r, ok := roots[dir]; ok {
+			return r
+		}
+		within := func(base string) bool {
+			_, err := gocore.Subdir(base, dir)
+			return err == nil
+		}
+		r := dir
+		switch {
+		case dirmod != dirstd && within(dirmod): // keep the module's packages expanded
+		case within(dirstd):
+			if flags.depsOnlyStd {
+				r = dirstd
+			}
+		case strings.Contains(dir, "/vendor/"): // vendored packages keep their own nodes
+		case within(dirimps):
+			if b, _, ok := strings.Cut(verspath(dir), "@"); ok {
+				r = b // the module's directory precedes its version
+			}
+		}
+		roots[dir] = r
+		return r
+	}
+
+	collapsed := linkset{}
+	for lk, syms := range lks {
+		lk = link{from: root(lk.from), to: root(lk.to)}
+		if _, ok := collapsed[lk]; !ok {
+			collapsed[lk] = tree{}
+		}
+		for sym := range syms {
+			collapsed[lk].Add(sym)
+		}
+	}
+	return collapsed
+}
+
+// vendored collapses the dependencies of the vendored packages onto the vendor directory
+// that contains them, or hides the dependencies from and to vendored packages.
+func (lks linkset) vendored(mode string) linkset {
+	vendor := func(dir string) (string, bool) {
+		if v, _, ok := strings.Cut(dir, "/vendor/"); ok {
+			return v + "/vendor", true
+		}
+		return dir, false
+	}
+
+	vendored := linkset{}
+	for lk, syms := range lks {
+		from, fv := vendor(lk.from)
+		to, tv := vendor(lk.to)
+		if mode == "hide" && (fv || tv) {
+			continue
+		}
+		if mode == "collapse" {
+			lk = link{from: from, to: to}
+		}
+		if _, ok := vendored[lk]; !ok {
+			vendored[lk] = tree{}
+		}
+		for sym := range syms {
+			vendored[lk].Add(sym)
+		}
+	}
+	return vendored
+}
+
+// truncate merges the dependencies of the packages whose paths within their top-level
+// subgraph exceed a depth onto the package directory at that depth, e.g. at depth 2
+// both a/b/c and a/b/d onto a/b.
+func (lks linkset) truncate(depth int) linkset {
+	shallow := func(dir string) string {
+		if _, pkg, ok := identify(dir); ok && pkg != "." {
+			for n := strings.Count(pkg, "/") + 1; n > depth; n-- {
+				dir = filepath.Dir(dir)
+			}
+		}
+		return dir
+	}
+
+	truncated := linkset{}
+	for lk, syms := range lks {
+		lk = link{from: shallow(lk.from), to: shallow(lk.to)}
+		if _, ok := truncated[lk]; !ok {
+			truncated[lk] = tree{}
+		}
+		for sym := range syms {
+			truncated[lk].Add(sym)
+		}
+	}
+	return truncated
+}
@@ -0,0 +1,705 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zosmac/gocore"
+)
+
+var (
+	// cwd current working directory with module source.
+	cwd, _ = os.Getwd()
+
+	// stdout receives the primary artifact: standard output, or the -o file.
+	stdout = os.Stdout
+)
+
+// canonicalize value/reference types to same name to sort together.
+func canonicalize(node string, _ table) string {
+	return strings.Trim(node, "*()")
+}
+
+// display returns a function to write a tree node to w based on recursion depth.
+// Subtrees below the -report-depth are truncated, shown as "...".
+func display(w io.Writer) func(int, string, table) {
+	var truncated bool
+	return func(depth int, node string, _ table) {
+		if flags.reportDepth > 0 && depth >= flags.reportDepth {
+			if !truncated {
+				fmt.Fprintf(w, "%s...\n", strings.Repeat("\t", flags.reportDepth))
+				truncated = true
+			}
+			return
+		}
+		truncated = false
+		fmt.Fprintf(w, "%s%s\n", strings.Repeat("\t", depth), node)
+	}
+}
+
+// Main runs the godep command, called from gocore.Main by the main package.
+func Main(ctx context.Context) error {
+	if err := validate(); err != nil {
+		return err
+	}
+	a := newanalyzer()
+
+	if flags.output != "" {
+		f, err := os.Create(flags.output)
+		if err != nil {
+			return gocore.Error("output", err, map[string]string{
+				"file": flags.output,
+			})
+		}
+		defer f.Close()
+		stdout = f
+	}
+
+	if flags.compareModules != "" {
+		return compare(ctx, stdout, flags.compareModules)
+	}
+
+	if flags.semverImpact != "" {
+		return semverimpact(ctx, stdout, flags.semverImpact)
+	}
+
+	var zipped string // module path of the -zip
+	if flags.zip != "" {
+		tmp, root, mod, err := unzip(flags.zip)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		cwd, zipped = root, mod
+	}
+
+	if err := locate(zipped); err != nil {
+		return err
+	}
+
+	if flags.platforms != "" {
+		if err := platforms(ctx, stdout); err != nil {
+			return gocore.Error("platforms", err)
+		}
+		return nil
+	}
+
+	if flags.moduleGraph {
+		g, err := modulegraph(ctx)
+		if err != nil {
+			return err
+		}
+		if err := a.transform(g); err != nil {
+			return err
+		}
+		return a.render(g)
+	}
+
+	var lks linkset
+	if flags.fromGoList {
+		var parsed linkset
+		if flags.withTrees {
+			if err := a.analyze(ctx); err != nil {
+				return err
+			}
+			parsed = links(a.refs)
+			clear(a.imps) // the go tool resolves the imports
+		}
+		var err error
+		if lks, err = a.golist(os.Stdin); err != nil {
+			return gocore.Error("go list", fmt.Errorf("%w: %w", ErrLoadFailed, err))
+		}
+		for lk := range lks {
+			if syms, ok := parsed[lk]; ok {
+				lks[lk] = syms
+			}
+		}
+	} else {
+		if err := a.analyze(ctx); err != nil {
+			return err
+		}
+		lks = links(a.refs)
+	}
+
+	// enforce the rules on the complete dependencies, before the flags that narrow the view
+	err := a.enforce(lks)
+	if err == nil {
+		err = a.strict()
+	}
+
+	if flags.sort != "name" {
+		a.rank(lks)
+	}
+
+	a.report(os.Stderr)
+	sum := a.summarize(lks)
+	sum.report(os.Stderr)
+	cyclesreport(os.Stderr, cycles(lks))
+
+	if flags.similar > 0 {
+		a.similarities()
+	}
+
+	if flags.similarInterfaces > 0 {
+		a.similarinterfaces()
+	}
+
+	if flags.metrics {
+		a.metrics(lks)
+	}
+
+	if flags.stdReport {
+		stdreport(lks)
+	}
+
+	if flags.unusedImports {
+		a.unused()
+	}
+
+	if flags.explainBuild {
+		a.explain()
+	}
+
+	if flags.unreferenced {
+		a.unreferenced(lks)
+	}
+
+	if flags.files {
+		a.filesreport()
+	}
+
+	if flags.implementsMatrix {
+		a.implementsmatrix()
+	}
+
+	if flags.manifests != "" {
+		if err := manifests(lks, flags.manifests); err != nil {
+			return err
+		}
+	}
+
+	if flags.json {
+		if err := a.encode(stdout, lks); err != nil {
+			return gocore.Error("json", err)
+		}
+		return err
+	}
+
+	if flags.summaryJSON {
+		if err := sum.encode(stdout); err != nil {
+			return gocore.Error("json", err)
+		}
+		return err
+	}
+
+	if flags.modulesList {
+		moduleslist(stdout, lks)
+		return err
+	}
+
+	if flags.commands {
+		roots := a.commandroots()
+		for _, dir := range roots {
+			a.highlights[dir] = "peripheries=2 penwidth=2"
+		}
+		lks.closure(roots)
+	}
+
+	if flags.rootAt != "" {
+		dir, ok := lks.resolve(flags.rootAt)
+		if !ok {
+			return gocore.Error("root-at", errors.New("package not found"), map[string]string{
+				"package": flags.rootAt,
+			})
+		}
+		lks.closure([]string{dir})
+		a.apex = dir
+	}
+
+	if flags.owners != "" {
+		owners, err := loadowners(flags.owners)
+		if err != nil {
+			return err
+		}
+		lks.crossing(owners)
+		a.register(teamcolors{owners: owners})
+	}
+
+	if flags.coverage != "" {
+		cov, err := loadcoverage(flags.coverage)
+		if err != nil {
+			return err
+		}
+		a.register(cov)
+	}
+
+	if flags.since != "" {
+		since, err := parsesince(flags.since)
+		if err != nil {
+			return err
+		}
+		a.register(recent{since: since, origins: a.origins, blames: map[string][]time.Time{}})
+	}
+
+	if flags.changed != "" {
+		changed, err := changes(flags.changed)
+		if err != nil {
+			return err
+		}
+		lks.impact(changed, a.highlights)
+	}
+
+	if flags.reduce {
+		lks.reduce()
+	}
+
+	if flags.depsOnly {
+		lks = lks.collapse()
+	}
+
+	if flags.vendor != "show" {
+		lks = lks.vendored(flags.vendor)
+	}
+
+	if flags.depth > 0 {
+		lks = lks.truncate(flags.depth)
+	}
+
+	if flags.condense {
+		sccs := cycles(lks)
+		lks = lks.condense(sccs)
+		a.register(condensation(sccs))
+	}
+
+	if !flags.noCycleHighlight {
+		a.register(cyclic(cycles(lks)))
+	}
+
+	if flags.colorMap != "" {
+		if err := a.loadcolors(flags.colorMap); err != nil {
+			return err
+		}
+	}
+
+	if flags.focus != "" {
+		dir, ok := lks.resolve(flags.focus)
+		if !ok {
+			return gocore.Error("focus", errors.New("package not found"), map[string]string{
+				"package": flags.focus,
+			})
+		}
+		a.register(focus{dir: dir, radius: flags.focusDepth})
+	}
+
+	if flags.noStd && dirmod != dirstd {
+		a.register(nostd{})
+	}
+
+	if flags.only != "" {
+		a.register(only{prefix: flags.only})
+	}
+
+	g := a.model(lks)
+	if err := a.transform(g); err != nil {
+		return err
+	}
+
+	if flags.colorMap != "" {
+		if err := a.savecolors(flags.colorMap, g); err != nil {
+			return err
+		}
+	}
+
+	if err := a.render(g); err != nil {
+		return err
+	}
+
+	return err
+}
+
+// locate identifies the module of the current directory, or the standard library, whose
+// packages to analyze. A module zip without a go.mod has the module path of its prefix.
+func locate(zipped string) error {
+	dirmap = map[string]string{ // forget the directories of a module located before
+		dirstd:  standard,
+		dirimps: imports,
+	}
+	workspace = map[string]string{}
+	required = sync.OnceValue(requires)
+
+	if cwd == dirstd {
+		gomod, dirmod = standard, dirstd
+	} else if module := gocore.Module(cwd); module.Dir == "" && zipped != "" {
+		gomod, dirmod = zipped, cwd // a module zip without a go.mod
+		dirmap[dirmod] = gomod
+	} else {
+		if module.Dir == "" {
+			return gocore.Error("go.mod", ErrNoModule, map[string]string{
+				"directory": cwd,
+			})
+		}
+		gomod = module.Path
+		dirmod = module.Dir
+		dirmap[dirmod] = gomod
+		if zipped == "" {
+			return loadworkspace()
+		}
+	}
+	return nil
+}
+
+// render serializes the Graph and writes it to stdout or the -o file in the -format.
+// Without the Graphviz dot command to render the graph, render writes its dot source.
+func (a *analyzer) render(g *Graph) error {
+	switch flags.format { // serialized for other tools rather than Graphviz
+	case "mermaid":
+		stdout.WriteString(mermaid(g))
+		return nil
+	case "graphml":
+		out, err := graphML(g)
+		if err != nil {
+			return gocore.Error("graphml", err)
+		}
+		stdout.Write(out)
+		return nil
+	}
+
+	graph, err := a.nodegraph(g)
+	if err != nil {
+		return err
+	}
+	if _, err := exec.LookPath("dot"); err != nil && flags.format != "dot" {
+		gocore.Error("dot", fmt.Errorf("%w: %w", ErrDotMissing, err), map[string]string{
+			"format":   flags.format,
+			"fallback": "writing the graphviz source",
+		}).Warn()
+		stdout.WriteString(graph)
+		return nil
+	}
+
+	switch flags.format {
+	case "dot":
+		stdout.WriteString(graph)
+	case "report-html":
+		svg, err := dot(graph, "svg")
+		if err != nil {
+			return err
+		}
+		stdout.Write(a.reporthtml(svg))
+	default:
+		out, err := dot(graph, flags.format)
+		if err != nil {
+			return err
+		}
+		stdout.Write(out)
+	}
+	return nil
+}
+
+// commandroots lists the directories of the module's commands, the roots of -commands.
+func (a *analyzer) commandroots() []string {
+	var roots []string
+	for dir := range a.commands {
+		if _, err := gocore.Subdir(dirmod, dir); err == nil {
+			roots = append(roots, dir)
+		}
+	}
+	return roots
+}
+
+// analyze parses the module and its imports to build the trees.
+func (a *analyzer) analyze(ctx context.Context) error {
+	if flags.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, flags.timeout)
+		defer cancel()
+	}
+
+	if err := a.walk(ctx, cwd); err != nil && ctx.Err() == nil {
+		return gocore.Error("WalkDir", fmt.Errorf("%w: %w", ErrLoadFailed, err), map[string]string{
+			"directory": cwd,
+		})
+	}
+
+	for _, mod := range workspaces() {
+		if err := a.walk(ctx, workspace[mod]); err != nil && ctx.Err() == nil {
+			return gocore.Error("WalkDir", fmt.Errorf("%w: %w", ErrLoadFailed, err), map[string]string{
+				"directory": workspace[mod],
+			})
+		}
+	}
+
+	a.imps.Traverse(0, nil, canonicalize, func(_ int, node string, _ table) {
+		for pth := range a.imps[node] {
+			a.walk(ctx, pth)
+		}
+	})
+
+	if err := ctx.Err(); err != nil {
+		a.incomplete = true
+		gocore.Error("timeout", err, map[string]string{
+			"timeout": flags.timeout.String(),
+		}).Warn()
+	}
+
+	a.defs4refs()
+
+	a.typesets(ctx)
+
+	return nil
+}
+
+// walk the directory tree and parse the go files, stopping if the context is done.
+func (a *analyzer) walk(ctx context.Context, pth string) error {
+	if _, err := gocore.Subdir(dirimps, pth); err == nil {
+		pth = verspath(pth) // imports include version in path
+	}
+
+	var dirs []string
+	if err := filepath.WalkDir(
+		pth,
+		func(dir string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return fmt.Errorf("error walking %q at %s: %w", pth, dir, err)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				base := path.Base(entry.Name())
+				if _, ok := a.skips[base]; (ok || base[0] == '.') && dir != pth { // the root is the module's
+					return filepath.SkipDir
+				}
+				if a.unparsed(dir) {
+					dirs = append(dirs, dir)
+				}
+			}
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+
+	for i, p := range a.parseall(ctx, dirs) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		a.parse(dirs[i], p)
+	}
+	return nil
+}
+
+// defs4refs adds the definition location for each referenced type, value, or function.
+func (a *analyzer) defs4refs() {
+	defined := map[string]struct{}{} // directory and name of each definition, whatever its qualifier
+	for def, dirs := range a.defs {
+		_, name := qualified(def)
+		for dir := range dirs {
+			defined[dir+" "+name] = struct{}{}
+		}
+	}
+
+	for ref, abss := range a.refs {
+		for abs := range abss { // check if reference is from module or its workspace
+			if !inmodule(abs) {
+				delete(abss, abs) // remove reference
+			}
+		}
+		if len(abss) == 0 { // skip references only within std and imports
+			delete(a.refs, ref)
+			continue
+		}
+		if _, ok := a.defs[ref]; ok { // check if definition is in the current module
+			for def := range a.defs[ref] {
+				for abs := range abss {
+					abss[abs][def] = tree{}
+				}
+			}
+		} else { // add definition for standard or imported package type
+			pkg, name := qualified(ref)
+			for imp := range a.imps[pkg] {
+				// a module package qualified other than by its name, e.g. of directory a-go, defines the identifier
+				_, ok := defined[imp+" "+name]
+				for abs := range abss {
+					// a bare identifier of a file's dot imports is of the one that defines it
+					if _, dot := a.dotted[abs+" "+ref]; ok || !dot && !inmodule(imp) {
+						abss[abs][imp] = tree{}
+					}
+				}
+			}
+		}
+		for abs := range abss { // remove the dot imports that do not define a bare identifier
+			if _, dot := a.dotted[abs+" "+ref]; dot && len(abss[abs]) == 0 {
+				delete(abss, abs)
+			}
+		}
+		if len(abss) == 0 {
+			delete(a.refs, ref)
+			continue
+		}
+		a.refs[ref] = abss
+	}
+}
+
+// typesets finds the interfaces that types implement. The type checker decides whether a type
+// implements an interface, accounting for the parameter and result types and the receivers of
+// the methods; otherwise, e.g. if typechecking declines or the packages fail to load or are
+// generic, whether the type's methods include the normalized signatures of the interface's
+// methods.
+func (a *analyzer) typesets(ctx context.Context) {
+	var checked map[string]map[string]types.Type
+	if typechecking() {
+		checked = typecheck(ctx)
+	}
+	a.typechecked = checked != nil
+
+	// expand embedded interfaces with their methods
+	for ifc := range a.ifcs {
+		a.expand(ifc, map[string]struct{}{})
+	}
+
+	// for each type, check if it implements the methods of an interface
+	msets := map[string][]string{}
+	for ifc, mths := range a.ifcs {
+		msets[ifc] = methodset(mths)
+	}
+	for typ, flds := range a.typs {
+		fset := methodset(flds)
+		for ifc, mths := range a.ifcs {
+			ok, decided := implements(a.lookup(checked, typ), a.lookup(checked, ifc))
+			if !decided {
+				ok = subset(msets[ifc], fset)
+			}
+			if ok {
+				a.sets.Add(ifc, typ)
+				for _, mth := range msets[ifc] { // the evidence of satisfaction
+					a.sets[ifc][typ].Add(mth)
+				}
+			} else if _, ok := a.generics[typ]; ok && named(flds, mths) {
+				gocore.Error("typesets", errors.New("generic type method signatures differ from interface"), map[string]string{
+					"type":      typ,
+					"interface": ifc,
+				}).Warn()
+			}
+		}
+	}
+}
+
+// methodset orders the normalized signatures of a type's methods or an interface's methods.
+func methodset(mths tree) []string {
+	var mset []string
+	for mth := range mths {
+		mset = append(mset, strings.Join(strings.Fields(mth), " "))
+	}
+	sort.Strings(mset)
+	return mset
+}
+
+// subset reports whether all the elements of the ordered slice a are in the ordered slice b.
+func subset(a, b []string) bool {
+	i := 0
+	for _, s := range b {
+		if i < len(a) && a[i] == s {
+			i++
+		}
+	}
+	return i == len(a)
+}
+
+// named reports whether a type has methods with the names of all of an interface's methods.
+func named(flds, mths tree) bool {
+	names := map[string]struct{}{}
+	for fld := range flds {
+		if name, _, ok := strings.Cut(fld, "("); ok {
+			names[name] = struct{}{}
+		}
+	}
+	for mth := range mths {
+		name, _, _ := strings.Cut(mth, "(")
+		if _, ok := names[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// expand replaces an interface's embedded interfaces with their methods. Embedded interfaces
+// are expanded first so the method set is complete regardless of the order of expansion.
+func (a *analyzer) expand(ifc string, seen map[string]struct{}) {
+	if _, ok := seen[ifc]; ok {
+		return // guard against invalid recursive embedding
+	}
+	seen[ifc] = struct{}{}
+	mths := a.ifcs[ifc]
+	for mth := range mths {
+		if !strings.Contains(mth, "(") {
+			// embedded interface, replace with its methods
+			delete(mths, mth)
+			a.expand(mth, seen)
+			for m := range a.ifcs[mth] {
+				mths[m] = tree{}
+			}
+		}
+	}
+}
+
+// report echos out all of the trees to w.
+func (a *analyzer) report(w io.Writer) {
+	for t := range TREES {
+		heading := names[t]
+		if t == IMPLEMENTS {
+			heading = "TYPES FOR INTERFACES"
+		}
+		fmt.Fprintf(w, "==== %s ====\n", heading)
+		tbl, order := a.arrangement(t)
+		a.trees[t].Traverse(0, tbl, order, display(w))
+	}
+}
+
+// dot calls the Graphviz dot command to render the package dependencies in a format.
+func dot(graphviz, format string) ([]byte, error) {
+	cmd := exec.Command("dot", "-v", "-T"+format)
+	cmd.Stdin = bytes.NewBufferString(graphviz)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); errors.Is(err, exec.ErrNotFound) {
+		return nil, gocore.Error("dot", fmt.Errorf("%w: %w", ErrDotMissing, err))
+	} else if err != nil {
+		sc := bufio.NewScanner(strings.NewReader(graphviz))
+		for i := 1; sc.Scan(); i++ {
+			fmt.Fprintf(os.Stderr, "%4.d %s\n", i, sc.Text())
+		}
+		return nil, gocore.Error("dot", err, map[string]string{
+			"stderr": stderr.String(),
+		})
+	}
+
+	if flags.showDotWarnings {
+		sc := bufio.NewScanner(stderr)
+		for sc.Scan() { // skip the -v progress messages
+			if line := sc.Text(); strings.HasPrefix(line, "Warning") || strings.HasPrefix(line, "Error") {
+				fmt.Fprintln(os.Stderr, line)
+			}
+		}
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// run runs the godep command on a module of the testdata directory, returning what it writes
+// to standard output and to standard error, and its error.
+func run(t *testing.T, name string, setup func()) (string, string, error) {
+	t.Helper()
+	dir := fixture(t, name)
+	saved, wd, out, errs := flags, cwd, stdout, os.Stderr
+	defer func() {
+		flags, cwd, stdout, os.Stderr = saved, wd, out, errs
+	}()
+
+	tmp := t.TempDir()
+	var files [2]*os.File
+	for i, name := range []string{"stdout", "stderr"} {
+		f, err := os.Create(filepath.Join(tmp, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		files[i] = f
+	}
+	cwd, stdout, os.Stderr = dir, files[0], files[1]
+	setup()
+	err := Main(context.Background())
+
+	var streams [2]string
+	for i, f := range files {
+		data, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams[i] = string(data)
+	}
+	return streams[0], streams[1], err
+}
+
+func TestMainStreams(t *testing.T) {
+	_, err := exec.LookPath("dot")
+	rendered := func(prefix string) string {
+		if err != nil {
+			return "digraph " // without dot, the graphviz source
+		}
+		return prefix
+	}
+	for _, test := range []struct {
+		format string
+		prefix string
+	}{
+		{"dot", "digraph "},
+		{"xdot", "digraph "},
+		{"svg", rendered("<?xml")},
+		{"png", rendered("\x89PNG")},
+		{"pdf", rendered("%PDF")},
+		{"report-html", rendered("<!DOCTYPE html>")},
+		{"mermaid", "flowchart "},
+		{"graphml", "<?xml"},
+	} {
+		t.Run(test.format, func(t *testing.T) {
+			out, errs, err := run(t, "graph", func() { flags.format = test.format })
+			if err != nil {
+				t.Fatalf("Main() error = %v", err)
+			}
+			if !strings.HasPrefix(out, test.prefix) {
+				t.Errorf("stdout begins %q, want %q", out[:min(len(out), 40)], test.prefix)
+			}
+			if strings.Contains(out, "==== IMPORTS ====") {
+				t.Errorf("stdout contains the report")
+			}
+			if !strings.Contains(errs, "==== IMPORTS ====") {
+				t.Errorf("stderr lacks the report:\n%s", errs)
+			}
+		})
+	}
+}
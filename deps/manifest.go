@@ -0,0 +1,77 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// manifest lists the direct dependencies of a package, grouped by origin.
+	manifest struct {
+		Package string   `json:"package"`
+		Std     []string `json:"std"`
+		Module  []string `json:"module"`
+		Imports []string `json:"imports"`
+	}
+)
+
+// manifests writes for each module package a pkg.deps.json file to a directory tree
+// mirroring the module's packages, listing the import paths of the package's direct
+// dependencies, e.g. dir/cmd/tool.deps.json. The module's root package is named for
+// the last element of the module's path.
+func manifests(lks linkset, dir string) error {
+	mfs := map[string]*manifest{}
+	for _, lk := range lks.sorted() {
+		rel, err := gocore.Subdir(dirmod, lk.from)
+		if err != nil || lk.from == lk.to {
+			continue
+		}
+		mf, ok := mfs[rel]
+		if !ok {
+			mf = &manifest{Package: importpath(lk.from), Std: []string{}, Module: []string{}, Imports: []string{}}
+			mfs[rel] = mf
+		}
+		pth := importpath(lk.to)
+		switch tg, _, _ := identify(lk.to); tg {
+		case standard:
+			mf.Std = append(mf.Std, pth)
+		case gomod:
+			mf.Module = append(mf.Module, pth)
+		default:
+			mf.Imports = append(mf.Imports, pth)
+		}
+	}
+
+	for rel, mf := range mfs {
+		if rel == "." {
+			rel = path.Base(gomod)
+		}
+		for _, deps := range [][]string{mf.Std, mf.Module, mf.Imports} {
+			sort.Strings(deps)
+		}
+		name := filepath.Join(dir, rel+".deps.json")
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return gocore.Error("manifests", err)
+		}
+		f, err := os.Create(name)
+		if err != nil {
+			return gocore.Error("manifests", err)
+		}
+		err = encoder(f).Encode(mf)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return gocore.Error("manifests", err, map[string]string{
+				"file": name,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+// implementsmatrix reports the IMPLEMENTS tree as a matrix with a row for each of the module's
+// types and a column for each interface that one of those types implements. The columns are
+// numbered, keyed by the list of interfaces that precedes the matrix.
+func (a *analyzer) implementsmatrix() {
+	rows := map[string]map[string]struct{}{} // type:interfaces
+	cols := map[string]struct{}{}
+	for ifc, typs := range a.sets {
+		for typ := range typs {
+			if !a.defined(typ) {
+				continue
+			}
+			if _, ok := rows[typ]; !ok {
+				rows[typ] = map[string]struct{}{}
+			}
+			rows[typ][ifc] = struct{}{}
+			cols[ifc] = struct{}{}
+		}
+	}
+
+	var typs, ifcs []string
+	width := len("TYPE")
+	for typ := range rows {
+		typs = append(typs, typ)
+		width = max(width, len(typ))
+	}
+	for ifc := range cols {
+		ifcs = append(ifcs, ifc)
+	}
+	sort.Strings(typs)
+	sort.Strings(ifcs)
+
+	fmt.Fprintln(os.Stderr, "==== IMPLEMENTS MATRIX ====")
+	header := fmt.Sprintf("%-*s", width, "TYPE")
+	for i, ifc := range ifcs {
+		fmt.Fprintf(os.Stderr, "%3d  %s\n", i+1, ifc)
+		header += fmt.Sprintf(" %3d", i+1)
+	}
+	fmt.Fprintln(os.Stderr, header)
+	for _, typ := range typs {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%-*s", width, typ)
+		for _, ifc := range ifcs {
+			mark := "."
+			if _, ok := rows[typ][ifc]; ok {
+				mark = "X"
+			}
+			fmt.Fprintf(&sb, " %3s", mark)
+		}
+		fmt.Fprintln(os.Stderr, sb.String())
+	}
+}
+
+// defined reports whether a type is declared by one of the module's packages.
+func (a *analyzer) defined(typ string) bool {
+	for dir := range a.defs[typ] {
+		if _, err := gocore.Subdir(dirmod, dir); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+type (
+	// cluster is a Mermaid subgraph of the package nodes and nested subgraphs within a path prefix.
+	cluster struct {
+		label    string
+		nodes    []*Node
+		clusters map[string]*cluster
+	}
+
+	// mermaidids maps the kind and name of each node and subgraph to its Mermaid identifier.
+	mermaidids map[string]string
+)
+
+// mermaid produces the package connections as a Mermaid flowchart. Its subgraphs mirror
+// those that node places in the Graphviz node graph: a subgraph for each top-level subgraph,
+// nested subgraphs for the package path prefixes within it, and a package whose path is
+// itself a prefix, e.g. io of io/fs, placed within the prefix's subgraph.
+func mermaid(g *Graph) string {
+	prefixes := map[string]struct{}{} // cluster: path prefix
+	for _, n := range g.Nodes {
+		for pkg := path.Dir(n.Path); pkg != "." && pkg != "/"; pkg = path.Dir(pkg) {
+			prefixes[n.Cluster+": "+pkg] = struct{}{}
+		}
+	}
+
+	ids := mermaidids{}
+	top := map[string]*cluster{
+		standard: {label: "Go Standard Packages"},
+		imports:  {label: "Imported/Vendored Packages"},
+	}
+	tgs := []string{standard}
+	if dirmod != dirstd { // the standard library's packages are those of the standard subgraph
+		top[gomod] = &cluster{label: gomod}
+		tgs = append(tgs, gomod)
+	}
+	tgs = append(append(tgs, workspaces()...), imports)
+	for _, mod := range workspaces() {
+		top[mod] = &cluster{label: mod}
+	}
+	var dirs []string
+	for dir := range g.Nodes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		n := g.Nodes[dir]
+		cl, ok := top[n.Cluster]
+		if !ok {
+			continue
+		}
+		ids.add("n", n.Name)
+		pkg := ""
+		for _, seg := range strings.Split(n.Path, "/") {
+			pkg = path.Join(pkg, seg)
+			if _, ok := prefixes[n.Cluster+": "+pkg]; !ok {
+				break
+			}
+			if cl.clusters == nil {
+				cl.clusters = map[string]*cluster{}
+			}
+			sub, ok := cl.clusters[pkg]
+			if !ok {
+				sub = &cluster{label: pkg}
+				ids.add("c", n.Cluster+": "+pkg)
+				cl.clusters[pkg] = sub
+			}
+			cl = sub
+		}
+		cl.nodes = append(cl.nodes, n)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, tg := range tgs {
+		if cl := top[tg]; cl.count() > 0 {
+			cl.write(&sb, tg, "", ids, 1)
+		}
+	}
+
+	var lines []string
+	for _, e := range g.Edges {
+		rn, dn := g.Nodes[e.From], g.Nodes[e.To]
+		if rn == nil || dn == nil { // removed by a transformer
+			continue
+		}
+		arrow := "-->"
+		if strings.Contains(e.Attrs, "style=dashed") {
+			arrow = "-.->"
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s\n", ids["n "+rn.Name], arrow, ids["n "+dn.Name]))
+	}
+	sort.Strings(lines)
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// count totals the package nodes within a cluster and its nested clusters.
+func (cl *cluster) count() int {
+	n := len(cl.nodes)
+	for _, sub := range cl.clusters {
+		n += sub.count()
+	}
+	return n
+}
+
+// write appends a cluster's subgraph statement, its nodes, and its nested subgraphs.
+func (cl *cluster) write(sb *strings.Builder, tg, pkg string, ids mermaidids, depth int) {
+	indent := strings.Repeat("  ", depth)
+	id := mermaidid("c", tg) // the nested subgraphs' names add their prefixes
+	if pkg != "" {
+		id = ids["c "+tg+": "+pkg]
+	}
+	fmt.Fprintf(sb, "%ssubgraph %s[\"%s (%d)\"]\n", indent, id, mermaidlabel(cl.label), cl.count())
+	for _, n := range cl.nodes {
+		fmt.Fprintf(sb, "%s  %s[\"%s\"]\n", indent, ids["n "+n.Name], mermaidlabel(ellipsize(n.Label)))
+	}
+	var pkgs []string
+	for pkg := range cl.clusters {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		cl.clusters[pkg].write(sb, tg, pkg, ids, depth+1)
+	}
+	fmt.Fprintf(sb, "%send\n", indent)
+}
+
+// add assigns a node or subgraph, by kind n or c, the sanitized Mermaid identifier of its
+// name, distinguished by a suffix from the names that sanitize alike, e.g. a-b and a.b.
+func (ids mermaidids) add(kind, name string) {
+	key := kind + " " + name
+	if _, ok := ids[key]; ok {
+		return
+	}
+	id := mermaidid(kind, name)
+	for i := 2; ids.used(id); i++ {
+		id = fmt.Sprintf("%s_%d", mermaidid(kind, name), i)
+	}
+	ids[key] = id
+}
+
+// used reports whether a Mermaid identifier is assigned.
+func (ids mermaidids) used(id string) bool {
+	for _, used := range ids {
+		if used == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mermaidid sanitizes a name to a Mermaid identifier of letters, digits, and underscores.
+func mermaidid(kind, name string) string {
+	return kind + "_" + strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// mermaidlabel prepares text for inclusion in a quoted Mermaid label.
+func mermaidlabel(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br>").Replace(s)
+}
@@ -0,0 +1,63 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// coupling measures a module package's dependencies. Afferent couplings count the module
+	// packages that depend on the package, efferent couplings the packages that it depends on,
+	// and instability is Ce/(Ca+Ce), from 0 for a stable foundation to 1 for a dependent leaf.
+	coupling struct {
+		Afferent    int     `json:"ca"`
+		Efferent    int     `json:"ce"`
+		Instability float64 `json:"instability"`
+		Cohesion    int     `json:"cohesion"`
+	}
+)
+
+// couplings measures each module package, keyed by import path.
+func (a *analyzer) couplings(lks linkset) map[string]coupling {
+	ca, ce := fanins(lks), fanouts(lks)
+	cps := map[string]coupling{}
+	for dir := range a.parsedDirs {
+		if _, err := gocore.Subdir(dirmod, dir); err != nil {
+			continue
+		}
+		cp := coupling{Afferent: ca[dir], Efferent: ce[dir], Cohesion: a.cohesion[dir]}
+		if n := cp.Afferent + cp.Efferent; n > 0 {
+			cp.Instability = float64(cp.Efferent) / float64(n)
+		}
+		cps[importpath(dir)] = cp
+	}
+	return cps
+}
+
+// metrics reports per module package measures to stderr, from most to least unstable.
+// Internal cohesion counts the references within a package to its own package level declarations.
+func (a *analyzer) metrics(lks linkset) {
+	cps := a.couplings(lks)
+	var pkgs []string
+	for pkg := range cps {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		ci, cj := cps[pkgs[i]], cps[pkgs[j]]
+		return ci.Instability > cj.Instability ||
+			ci.Instability == cj.Instability && pkgs[i] < pkgs[j]
+	})
+
+	fmt.Fprintln(os.Stderr, "==== METRICS ====")
+	fmt.Fprintf(os.Stderr, "%4s  %4s  %11s  %8s  %s\n", "CA", "CE", "INSTABILITY", "COHESION", "PACKAGE")
+	for _, pkg := range pkgs {
+		cp := cps[pkg]
+		fmt.Fprintf(os.Stderr, "%4d  %4d  %11.2f  %8d  %s\n",
+			cp.Afferent, cp.Efferent, cp.Instability, cp.Cohesion, pkg)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+// modulegraph builds the Graph of the module requirements reported by `go mod graph`.
+// Each module version is a node, clustered by the segments of its path, and each
+// requirement is an edge from the requiring module to the required module version.
+func modulegraph(ctx context.Context) (*Graph, error) {
+	cmd := exec.CommandContext(ctx, "go", "mod", "graph")
+	cmd.Dir = dirmod
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, gocore.Error("go mod graph", err, map[string]string{
+			"stderr": stderr.String(),
+		})
+	}
+
+	g := &Graph{Nodes: map[string]*Node{}}
+	module := func(mod string) string {
+		if _, ok := g.Nodes[mod]; !ok {
+			n := &Node{
+				Dir:     mod,
+				Cluster: imports,
+				Path:    mod,
+				Label:   mod,
+			}
+			if mod == gomod {
+				n.Cluster, n.Path = gomod, "."
+			}
+			n.Name = n.Cluster + ": " + n.Label
+			n.Color = color(n.Name)
+			g.Nodes[mod] = n
+		}
+		return mod
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		from, to, ok := strings.Cut(sc.Text(), " ")
+		if !ok {
+			continue
+		}
+		e := &Edge{From: module(from), To: module(to)}
+		e.Color = g.Nodes[from].Color + ";0.5:" + g.Nodes[to].Color
+		g.Edges = append(g.Edges, e)
+	}
+
+	return g, nil
+}
@@ -0,0 +1,104 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zosmac/gocore"
+)
+
+// moduleslist writes the distinct paths of the third-party modules whose packages the module
+// imports, one per line, e.g. for go mod why -m.
+func moduleslist(w io.Writer, lks linkset) {
+	vendored := vendormodules()
+	mods := map[string]struct{}{}
+	for lk := range lks {
+		if _, err := gocore.Subdir(dirmod, lk.from); err != nil {
+			continue
+		}
+		if tg, _, _ := identify(lk.to); tg != imports {
+			continue
+		}
+		if mod := modulepath(lk.to, vendored); mod != "" {
+			mods[mod] = struct{}{}
+		}
+	}
+
+	var sorted []string
+	for mod := range mods {
+		sorted = append(sorted, mod)
+	}
+	sort.Strings(sorted)
+	for _, mod := range sorted {
+		fmt.Fprintln(w, mod)
+	}
+}
+
+// modulepath determines the path of the module that owns an imported package directory:
+// from the module cache layout, where the module's directory carries its version, or for
+// a vendored package, from the longest matching module path of vendor/modules.txt.
+func modulepath(dir string, vendored []string) string {
+	if _, pth, ok := strings.Cut(dir, "/vendor/"); ok {
+		for _, mod := range vendored { // longest first
+			if pth == mod || strings.HasPrefix(pth, mod+"/") {
+				return mod
+			}
+		}
+		return ""
+	}
+	b, _, ok := strings.Cut(verspath(dir), "@")
+	if !ok {
+		return ""
+	}
+	rel, err := gocore.Subdir(dirimps, b)
+	if err != nil {
+		return ""
+	}
+	return unescape(filepath.ToSlash(rel))
+}
+
+// vendormodules reads the module paths listed by the module's vendor/modules.txt, longest first.
+func vendormodules() []string {
+	f, err := os.Open(filepath.Join(dirmod, "vendor", "modules.txt"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var mods []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if fields := strings.Fields(sc.Text()); len(fields) > 1 && fields[0] == "#" {
+			mods = append(mods, fields[1])
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool {
+		return len(mods[i]) > len(mods[j])
+	})
+	return mods
+}
+
+// unescape reverses the module cache's case encoding of a module path, where !x denotes X.
+func unescape(pth string) string {
+	var sb strings.Builder
+	bang := false
+	for _, r := range pth {
+		if bang {
+			r = unicode.ToUpper(r)
+			bang = false
+		} else if r == '!' {
+			bang = true
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,616 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"hash/fnv"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zosmac/gocore"
+)
+
+var (
+	// the top-level subgraphs.
+	standard, imports = "std", "import"
+
+	// dirmap maps the source directory paths to their subgraphs.
+	dirmap = map[string]string{
+		dirstd:  standard,
+		dirimps: imports,
+	}
+
+	// subgtmpl is the layout for a graphviz subgraph statement. The %c
+	// formatter at the beginning is for a character to facilitate sorting
+	// the subgraph and node statements and closing characters '}' for a
+	// subgraph and ']' for a node's tooltip. Use \x00 to sort subgraph
+	// statements first. Use \x7F to sort closing characters ']' and '}'
+	// last. Trim this character when inserting into the nodegraph.
+	subgtmpl = "%c\nsubgraph %q { cluster=true fontcolor=black bgcolor=%q label=%q %s"
+
+	// nodetmpl is the layout for a graphviz node statement. The initial
+	// pad space character is trimmed from each statement as it is inserted
+	// into the graphviz nodegraph. The tooltip opens with the package's
+	// resolved source directory.
+	nodetmpl = " \n%q [fillcolor=%q label=%q%s tooltip=\"%s\\n"
+
+	// colors on HSV spectrum that work well in light and dark mode
+	colors = []string{
+		"0.0 0.5 0.80",
+		"0.1 0.5 0.75",
+		"0.2 0.5 0.7 ",
+		"0.3 0.5 0.75",
+		"0.4 0.5 0.75",
+		"0.5 0.5 0.75",
+		"0.6 0.5 0.9 ",
+		"0.7 0.5 1.0", // blue needs to be a bit brighter
+		"0.8 0.5 0.9",
+		"0.9 0.5 0.85",
+	}
+
+	// hash used to compute colors index
+	hash = fnv.New64()
+)
+
+type (
+	// layout is the state of the serialization of a Graph as a graphviz node graph. A fresh
+	// layout per serialization begins with no subgraphs, nodes, or edges.
+	layout struct {
+		// graphmap maps standard, (module), and imports/vendor packages to the top graphvis subgraphs.
+		graphmap map[string]string
+
+		// subgmap maps the 'branch' package paths to graphvis subgraph statements.
+		subgmap map[string]string
+
+		// nodemap maps the 'leaf' package paths to graphviz node statements.
+		nodemap map[string]string
+
+		// ids maps, with -int-ids, the graphviz node names to integer identifiers.
+		ids map[string]string
+
+		// tallies counts the package nodes within each subgraph, keyed as nodemap.
+		tallies map[string]int
+
+		// nodes contains the graphviz layout of subgraphs and nodes.
+		nodes tree
+
+		// edges contains all the links between nodes.
+		edges tree
+	}
+)
+
+// color defines the color for graphviz nodes and edges
+func color(s string) string {
+	hash.Write([]byte(s))
+	i := hash.Sum64()
+	hash.Reset()
+	return colors[i%uint64(len(colors))]
+}
+
+// newlayout creates the empty layout of a serialization.
+func newlayout() *layout {
+	return &layout{
+		graphmap: map[string]string{},
+		subgmap:  map[string]string{},
+		nodemap:  map[string]string{},
+		ids:      map[string]string{},
+		tallies:  map[string]int{},
+		nodes:    tree{},
+		edges:    tree{},
+	}
+}
+
+// nodegraph produces the package connections node graph, failing if a GraphTransformer placed
+// a Node in an unknown cluster.
+func (a *analyzer) nodegraph(g *Graph) (string, error) {
+	l := newlayout()
+	defer func() {
+		if r := recover(); r != nil {
+			buf := make([]byte, 4096)
+			n := runtime.Stack(buf, false)
+			buf = buf[:n]
+			gocore.Error("nodegraph", fmt.Errorf("%v", r), map[string]string{
+				"stacktrace": string(buf),
+			}).Err()
+		}
+	}()
+
+	for _, n := range g.Nodes {
+		l.tallies[n.Cluster]++
+		l.tallies[n.Cluster+": "+n.Path]++ // a parent package node moves into its children's subgraph
+		for pkg := path.Dir(n.Path); pkg != "." && pkg != "/"; pkg = path.Dir(pkg) {
+			l.tallies[n.Cluster+": "+pkg]++
+		}
+	}
+
+	l.graphmap[standard] = subgraph(0x01, standard, "", "lightgrey", l.tally("Go Standard Packages", standard),
+		"rank=same\n\"Standard Packages\" [color=white fillcolor=white fontcolor=black]")
+	l.graphmap[imports] = subgraph(0x03, imports, "", "lightgrey", l.tally("Imported/Vendored Packages", imports),
+		"rank=same\n\"Imported Packages\" [color=white fillcolor=white fontcolor=black]")
+	if dirmod != dirstd {
+		l.graphmap[gomod] = subgraph(0x02, gomod, "", "lightgrey", l.tally(gomod, gomod),
+			"rank=same\n\""+gomod+"\" [color=white fillcolor=white fontcolor=black]")
+	}
+	for _, mod := range workspaces() { // the other modules of the workspace follow the module
+		l.graphmap[mod] = subgraph(0x02, mod, "", "lightgrey", l.tally(mod, mod),
+			"rank=same\n\""+mod+"\" [color=white fillcolor=white fontcolor=black]")
+	}
+	if flags.focus != "" || flags.noStd || flags.only != "" { // omit the top-level subgraphs that the transformers emptied
+		for tg := range l.graphmap {
+			if l.tallies[tg] == 0 {
+				delete(l.graphmap, tg)
+			}
+		}
+	}
+	for _, gr := range l.graphmap {
+		l.nodes[gr] = tree{"\x7F\n}": tree{}}
+	}
+
+	var dirs, emphasized []string
+	for dir := range g.Nodes {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	if flags.intIDs {
+		var names []string
+		for _, n := range g.Nodes {
+			names = append(names, n.Name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			l.ids[name] = strconv.Itoa(i + 1)
+		}
+	}
+	for _, dir := range dirs {
+		n := g.Nodes[dir]
+		if _, ok := l.graphmap[n.Cluster]; !ok {
+			return "", gocore.Error("nodegraph", ErrUnknownCluster, map[string]string{
+				"node":    n.Name,
+				"cluster": n.Cluster,
+			})
+		}
+		l.node(n)
+		if n.Attrs != "" {
+			emphasized = append(emphasized, fmt.Sprintf("%q [%s]\n", l.id(n), n.Attrs))
+		}
+	}
+	sort.Strings(emphasized)
+
+	for _, e := range g.Edges {
+		rn, dn := g.Nodes[e.From], g.Nodes[e.To]
+		if rn == nil || dn == nil { // removed by a transformer
+			continue
+		}
+		r, rtree := l.node(rn)
+		d, dtree := l.node(dn)
+
+		rtree[" "+rn.Name+"\\n"] = tree{}
+		rtree[" "+dn.Name+"\\n"] = tree{}
+		dtree[" "+rn.Name+"\\n"] = tree{}
+		dtree[" "+dn.Name+"\\n"] = tree{}
+
+		dir := "back"
+		tport, hport := "e", "w" // 'e', 'w' ONLY way to ensure edge on correct side
+		if d < r {
+		} else if d > r {
+			dir = "forward"
+			tport, hport = "w", "e"
+		} else if d == 1 {
+			tport, hport = "w", "w"
+		} else {
+			tport, hport = "e", "e"
+		}
+
+		var attrs string
+		if e.Attrs != "" {
+			attrs = " " + e.Attrs
+		}
+
+		l.edges[fmt.Sprintf(
+			"\n%q -> %q [dir=%s tailport=%s headport=%s color=%q tooltip=\"%s\\n%s%s%s\"%s%s]",
+			l.id(dn),
+			l.id(rn),
+			dir,
+			tport,
+			hport,
+			e.Color,
+			dn.Name,
+			rn.Name,
+			symbols(e),
+			e.Tooltip,
+			classes(rn, dn),
+			attrs,
+		)] = tree{}
+	}
+
+	label := time.Now().Local().Format("Mon Jan 02 2006 at 03:04:05PM MST")
+	if flags.rev {
+		if rev := revision(); rev != "" {
+			label += " at revision " + rev
+		}
+	}
+	if a.incomplete {
+		label += " (INCOMPLETE: analysis timed out)"
+	}
+
+	graph := fmt.Sprintf(`digraph "Module \"%s\" Packages Nodegraph" {
+  label="\G %s"
+  labelloc=t
+  fontname="sans-serif"
+  fontsize=14.0
+  fontcolor=lightgrey
+  bgcolor=black
+  rankdir=LR
+  newrank=true
+  compound=true
+  ordering=out
+  nodesep=0.05
+  ranksep=8
+  node [shape=rect style="filled" height=0.3 width=%.2f margin="0.2,0.0" fontname="sans-serif" fontsize=11.0]
+  edge [penwidth=2.0]`,
+		gomod,
+		label,
+		flags.nodeMinWidth,
+	)
+
+	l.nodes.Traverse(0, nil, canonicalize, func(_ int, s string, _ table) {
+		graph += s[1:]
+	})
+
+	for _, nd := range emphasized {
+		graph += nd
+	}
+
+	if flags.statsPanel {
+		graph += statspanel(g)
+	}
+
+	if flags.legend {
+		graph += l.legend()
+	}
+
+	if n, ok := g.Nodes[a.apex]; ok {
+		graph += fmt.Sprintf("{ rank=min %q }\n", l.id(n))
+	}
+
+	type anchor struct {
+		tg, name string
+		order    int
+	}
+	anchors := []anchor{{standard, "Standard Packages", 1}}
+	if dirmod != dirstd {
+		anchors = append(anchors, anchor{gomod, gomod, 2})
+	}
+	for _, mod := range workspaces() {
+		anchors = append(anchors, anchor{mod, mod, 2})
+	}
+	anchors = append(anchors, anchor{imports, "Imported Packages", 3})
+	anchors = slices.DeleteFunc(anchors, func(an anchor) bool {
+		_, ok := l.graphmap[an.tg]
+		return !ok
+	})
+	for i := 1; i < len(anchors); i++ { // rank the top-level subgraphs left to right
+		graph += fmt.Sprintf("%q -> %q [style=invis ltail=%d lhead=%d]\n",
+			anchors[i-1].name, anchors[i].name, anchors[i-1].order, anchors[i].order)
+	}
+
+	l.edges.Traverse(0, nil, canonicalize, func(_ int, s string, _ table) {
+		graph += s
+	})
+
+	graph += "\n}\n"
+
+	return graph, nil
+}
+
+// subgraph formats a graphviz subgraph statement for a top-level subgraph or for a package
+// path prefix within one. With -collapsible, the subgraph has a stable identifier by which
+// the script in the report-html output collapses and expands it.
+func subgraph(order byte, tg, pkg, color, label, attrs string) string {
+	if flags.collapsible {
+		attrs += fmt.Sprintf(" id=%q class=\"collapsible\"", clusterid(tg, pkg))
+	}
+	return fmt.Sprintf(subgtmpl, order, cmp.Or(pkg, tg), color, label, attrs)
+}
+
+// id returns the graphviz node identifier of a Node: its name or, with -int-ids, an integer
+// from the sorted order of the names. The tooltips continue to identify nodes by name.
+func (l *layout) id(n *Node) string {
+	if id, ok := l.ids[n.Name]; ok {
+		return id
+	}
+	return n.Name
+}
+
+// tally appends to a subgraph's label the count of the package nodes within it, e.g. net (7).
+func (l *layout) tally(label, key string) string {
+	return fmt.Sprintf("%s (%d)", label, l.tallies[key])
+}
+
+// clusterid derives the stable identifier of a subgraph from its top-level subgraph and package path prefix.
+func clusterid(tg, pkg string) string {
+	return "cluster_" + strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' {
+			return r
+		}
+		return '_'
+	}, path.Join(tg, pkg))
+}
+
+// classes formats, with -collapsible, the class attribute that identifies the subgraphs
+// enclosing nodes, so that the nodes and their edges hide when a subgraph collapses.
+func classes(ns ...*Node) string {
+	if !flags.collapsible {
+		return ""
+	}
+	var class []string
+	seen := map[string]struct{}{}
+	for _, n := range ns {
+		ids := []string{clusterid(n.Cluster, "")}
+		for pkg := path.Dir(n.Path); pkg != "." && pkg != "/"; pkg = path.Dir(pkg) {
+			ids = append(ids, clusterid(n.Cluster, pkg))
+		}
+		for _, id := range ids {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				class = append(class, "in-"+id)
+			}
+		}
+	}
+	return fmt.Sprintf(" class=%q", strings.Join(class, " "))
+}
+
+// colorize assigns colors to nodes with a greedy graph coloring, ordered by
+// descending degree, so that adjacent nodes have distinct colors where possible.
+// A node whose neighbors exhaust the colors keeps its hashed color.
+func (a *analyzer) colorize(lks linkset) {
+	adjacent := map[string]map[string]struct{}{}
+	for lk := range lks {
+		from, to := nodename(lk.from), nodename(lk.to)
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		for _, pair := range [][2]string{{from, to}, {to, from}} {
+			if _, ok := adjacent[pair[0]]; !ok {
+				adjacent[pair[0]] = map[string]struct{}{}
+			}
+			adjacent[pair[0]][pair[1]] = struct{}{}
+		}
+	}
+
+	var names []string
+	for name := range adjacent {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(adjacent[names[i]]) > len(adjacent[names[j]]) ||
+			len(adjacent[names[i]]) == len(adjacent[names[j]]) && names[i] < names[j]
+	})
+
+	for _, name := range names {
+		if _, ok := a.palette[name]; ok {
+			continue // from the -color-map
+		}
+		used := map[string]struct{}{}
+		for adj := range adjacent[name] {
+			if c, ok := a.palette[adj]; ok {
+				used[c] = struct{}{}
+			}
+		}
+		for _, c := range colors {
+			if _, ok := used[c]; !ok {
+				a.palette[name] = c
+				break
+			}
+		}
+	}
+}
+
+// origin reports the first position in the referencing package where a dependency originates.
+func (a *analyzer) origin(lk link, syms tree) string {
+	var first token.Position
+	for sym := range syms {
+		if pos, ok := a.origins[lk.from][sym]; ok && (!first.IsValid() || before(pos, first)) {
+			first = pos
+		}
+	}
+	if !first.IsValid() {
+		return ""
+	}
+	if rel, err := gocore.Subdir(dirmod, first.Filename); err == nil {
+		first.Filename = rel
+	}
+	return "\\n" + escape(first.String())
+}
+
+// sites lists, with -positions, the positions of the references of a dependency's symbols
+// within the referencing package, relative to the module.
+func (a *analyzer) sites(lk link, syms tree) string {
+	var lines []string
+	for sym := range syms {
+		for pos := range a.positions[REFERENCES][sym][lk.from] {
+			if rel, err := gocore.Subdir(dirmod, pos); err == nil {
+				pos = rel
+			}
+			lines = append(lines, escape(sym+" at "+pos))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	sort.Strings(lines)
+	return "\\n" + strings.Join(lines, "\\n")
+}
+
+// width sizes a node to its label within the -node-min-width and -node-max-width bounds.
+// Absent a maximum, graphviz sizes the node, using the minimum from the node defaults.
+func width(label string) string {
+	if flags.nodeMaxWidth <= 0 {
+		return ""
+	}
+	w := float64(len(label))*6.0/72.0 + 0.4 // ~6pt per 11pt sans-serif character, plus margins
+	w = max(flags.nodeMinWidth, min(w, flags.nodeMaxWidth))
+	return fmt.Sprintf(" width=%.2f fixedsize=true", w)
+}
+
+// ellipsize truncates each line of a label to the -max-label characters, ending with an ellipsis.
+func ellipsize(label string) string {
+	if flags.maxLabel <= 0 {
+		return label
+	}
+	lines := strings.Split(label, "\n")
+	for i, line := range lines {
+		if r := []rune(line); len(r) > flags.maxLabel {
+			lines[i] = string(r[:max(flags.maxLabel-1, 0)]) + "…"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// symbols lists for an edge's tooltip the referenced identifiers that justify the dependency.
+func symbols(e *Edge) string {
+	syms := slices.Clone(e.Symbols) // a transformer may have appended to them
+	slices.Sort(syms)
+	var s string
+	for _, sym := range slices.Compact(syms) {
+		s += "\\n" + escape(sym)
+	}
+	return s
+}
+
+// escape prepares text for inclusion in a quoted graphviz string.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// roots orders the source directories of dirmap from most to least specific, so that a
+// package resolves to the same subgraph regardless of map iteration order (e.g. a module
+// in the module cache resolves to the module rather than to imports).
+func roots() []string {
+	var pths []string
+	for pth := range dirmap {
+		pths = append(pths, pth)
+	}
+	sort.Slice(pths, func(i, j int) bool {
+		return len(pths[i]) > len(pths[j]) ||
+			len(pths[i]) == len(pths[j]) && pths[i] < pths[j]
+	})
+	return pths
+}
+
+// identify resolves a package directory to its top-level subgraph and package path.
+func identify(abs string) (string, string, bool) {
+	for _, pth := range roots() {
+		tg := dirmap[pth]
+		pkg, err := gocore.Subdir(pth, abs) // get package name
+		if err != nil {
+			continue
+		}
+
+		if _, a, ok := strings.Cut(abs, "/vendor/"); ok { // treat content of vendor as import
+			return imports, a, true
+		}
+		if path.Base(abs) == "vendor" { // vendored packages collapsed by -vendor collapse
+			return imports, "vendor", true
+		}
+
+		return tg, filepath.ToSlash(pkg), true
+	}
+
+	return "", "", false
+}
+
+// nodename resolves a package directory to the name of its graphviz node.
+func nodename(abs string) string {
+	tg, pkg, ok := identify(abs)
+	if !ok {
+		return ""
+	}
+	if pkg == "." {
+		pkg = tg // package = module
+	}
+	return tg + ": " + pkg
+}
+
+// node places a Node in its subgraph, returning its subgraph order and tooltip tree.
+func (l *layout) node(n *Node) (byte, tree) {
+	tg, pkg := n.Cluster, n.Path
+
+	gr := l.graphmap[tg]
+	order := gr[0] // first byte corresponds to order of top graph standard, module, imports, vendored
+
+	tr := l.nodes[gr]
+
+	dirs := strings.Split(path.Dir(pkg), "/")
+	base := path.Base(pkg)
+	pkg = ""
+	for _, dir := range dirs {
+		if dir == "." {
+			break
+		}
+		pkg = path.Join(pkg, dir)
+		node := tg + ": " + pkg
+
+		// cache dot subgraph statement
+		sg, ok := l.subgmap[node]
+		if !ok {
+			sg = subgraph(0x00, tg, pkg, color(pkg), l.tally(pkg, node), "rank=same")
+			l.subgmap[node] = sg
+		}
+
+		// add dot subgraph statement to node graph
+		if _, ok := tr[sg]; !ok {
+			tr[sg] = tree{"\x7F\n}": tree{}}
+		}
+
+		// if previously added package node (e.g. io) is parent of this
+		// node (e.g. io/fs), move it (i.e. io) into this subgraph
+		if nd, ok := l.nodemap[node]; ok {
+			if n, ok := tr[nd]; ok {
+				delete(tr, nd)
+				tr[sg][nd] = n
+			}
+		}
+
+		tr = tr[sg]
+	}
+
+	if pkg = path.Join(pkg, base); pkg == "." {
+		pkg = tg // package = module
+	}
+	node := tg + ": " + pkg
+
+	// if nested node (e.g. io/fs) for this node already
+	// exists, place this node (i.e. io) in its subgraph.
+	if sg, ok := l.subgmap[node]; ok {
+		if _, ok := tr[sg]; !ok {
+			tr[sg] = tree{"\x7F\n}": tree{}}
+		}
+		tr = tr[sg]
+	}
+
+	// cache dot node statement
+	nd, ok := l.nodemap[node]
+	if !ok {
+		label := ellipsize(n.Label)
+		nd = fmt.Sprintf(nodetmpl, l.id(n), n.Color, label, width(label)+classes(n), escape(n.Dir)+n.Tooltip)
+		l.nodemap[node] = nd
+	}
+
+	// add dot node statement to dot subgraph
+	if _, ok := tr[nd]; !ok {
+		tr[nd] = tree{"\x7F\"]": tree{}} // close tooltip and node attributes
+	}
+	tr = tr[nd]
+
+	return order, tr
+}
@@ -0,0 +1,33 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"slices"
+	"strings"
+)
+
+type (
+	// only is the GraphTransformer that restricts the Graph to the packages whose import paths
+	// begin with a prefix of whole path elements, e.g. example.com/a matches example.com/a/b but
+	// not example.com/ab. Unlike focus, the packages that remain are not those connected.
+	only struct {
+		prefix string
+	}
+)
+
+// Transform removes the nodes of the packages outside of the prefix and the edges of the
+// removed nodes, so that an edge remains only if both of its packages match.
+func (o only) Transform(g *Graph) error {
+	prefix := strings.TrimSuffix(o.prefix, "/")
+	for dir := range g.Nodes {
+		pth := unversion(importpath(dir)) // module versions are not in import paths
+		if pth != prefix && !strings.HasPrefix(pth, prefix+"/") {
+			delete(g.Nodes, dir)
+		}
+	}
+	g.Edges = slices.DeleteFunc(g.Edges, func(e *Edge) bool {
+		return g.Nodes[e.From] == nil || g.Nodes[e.To] == nil
+	})
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// ownership maps package path prefixes to the teams that own them.
+	ownership map[string]string
+
+	// teamcolors is the GraphTransformer that colors each dependency by the pair of teams it connects.
+	teamcolors struct {
+		owners ownership
+	}
+)
+
+// loadowners reads a CODEOWNERS style file of package path prefixes and their teams, one
+// pair per line. A prefix is an import path, or relative to the module's root. Blank lines
+// and # comments are ignored.
+func loadowners(name string) (ownership, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, gocore.Error("owners", err)
+	}
+	defer f.Close()
+
+	owners := ownership{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		prefix := strings.Trim(fields[0], "/")
+		if prefix != gomod && !strings.HasPrefix(prefix, gomod+"/") {
+			prefix = path.Join(gomod, prefix)
+		}
+		owners[prefix] = fields[1]
+	}
+	if err := sc.Err(); err != nil {
+		return nil, gocore.Error("owners", err)
+	}
+	return owners, nil
+}
+
+// owner finds the team owning a package by the longest matching prefix of its import path.
+func (owners ownership) owner(pkg string) string {
+	for ; pkg != "." && pkg != "/"; pkg = path.Dir(pkg) {
+		if team, ok := owners[pkg]; ok {
+			return team
+		}
+	}
+	return ""
+}
+
+// crossing reduces the dependencies to those between module packages of different owners.
+func (lks linkset) crossing(owners ownership) {
+	for lk := range lks {
+		if !lk.internal() || owners.owner(importpath(lk.from)) == owners.owner(importpath(lk.to)) {
+			delete(lks, lk)
+		}
+	}
+}
+
+// Transform colors each dependency by the pair of teams that it connects.
+func (tc teamcolors) Transform(g *Graph) error {
+	for _, e := range g.Edges {
+		pair := tc.owners.owner(importpath(e.From)) + " -> " + tc.owners.owner(importpath(e.To))
+		e.Color = color(pair)
+		e.Tooltip += "\\n" + escape(pair)
+	}
+	return nil
+}
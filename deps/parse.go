@@ -0,0 +1,268 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zosmac/gocore"
+)
+
+var (
+	// skipdirs identifies the directories that every analysis ignores for parsing, to which
+	// each analysis adds those of its -skip.
+	skipdirs = map[string]struct{}{
+		"internal": {},
+		"testdata": {},
+	}
+)
+
+type (
+	// parsed is the result of parsing a directory.
+	parsed struct {
+		pkgs map[string]*ast.Package
+		err  error
+	}
+)
+
+// skipped finds the path segment, e.g. internal, that excludes a directory or import path
+// from parsing. Only whole segments match, so internals or internalize do not.
+func (a *analyzer) skipped(pth string) (string, bool) {
+	for _, seg := range strings.Split(pth, "/") {
+		if _, ok := a.skips[seg]; ok {
+			return seg, true
+		}
+	}
+	return "", false
+}
+
+// unparsed records that a directory is to be parsed, reporting whether it is neither
+// parsed already nor skipped. The segments of the directory's import path decide, so that
+// a module in a directory named e.g. testdata is parsed.
+func (a *analyzer) unparsed(dir string) bool {
+	if _, ok := a.parsedDirs[dir]; ok {
+		return false
+	}
+	a.parsedDirs[dir] = struct{}{}
+
+	_, ok := a.skipped(importpath(filepath.ToSlash(dir)))
+	return !ok
+}
+
+// parseall parses directories concurrently with up to -jobs workers, returning the results
+// in the order of the directories. Only the parsing is concurrent, as the parser's file set
+// is safe for concurrent use; walking the ASTs updates the shared trees, so the caller walks
+// them in order, exactly as if each directory was parsed in turn.
+func (a *analyzer) parseall(ctx context.Context, dirs []string) []parsed {
+	results := make([]parsed, len(dirs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(flags.jobs, len(dirs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := ctx.Err(); err != nil {
+					results[i].err = err
+					continue
+				}
+				results[i].pkgs, results[i].err = a.parsedir(ctx, dirs[i])
+			}
+		}()
+	}
+	for i := range dirs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// parse walks the AST of the packages parsed from a directory.
+func (a *analyzer) parse(dir string, p parsed) {
+	pkgs, err := p.pkgs, p.err
+	if err != nil {
+		a.failures[dir] = err
+		if errors.Is(err, context.DeadlineExceeded) { // parsedir reports its timeout
+			a.highlights[dir] = `style="filled,dashed" color=orange penwidth=3 xlabel="incomplete"`
+		} else {
+			gocore.Error("parse", err, map[string]string{
+				"directory": dir,
+			}).Warn()
+		}
+		if pkgs == nil { // otherwise, the syntax parsed before the errors still contributes
+			return
+		}
+	}
+
+	// evaluate build constraints first, so that only buildable packages compete, e.g. not
+	// a main package generator excluded by //go:build ignore from a library's directory
+	var primary []string
+	for name, pkg := range pkgs {
+		for pth, file := range pkg.Files {
+			if !a.gobuild(pth, file) {
+				delete(pkg.Files, pth)
+			}
+		}
+		if len(pkg.Files) == 0 {
+			delete(pkgs, name)
+		} else if !strings.HasSuffix(name, "_test") {
+			primary = append(primary, name)
+		}
+	}
+	if len(primary) > 1 {
+		sort.Strings(primary)
+		gocore.Error("parse", errors.New("multiple packages in directory"), map[string]string{
+			"directory": dir,
+			"packages":  strings.Join(primary, ","),
+		}).Warn()
+	}
+
+	for _, pkg := range pkgs {
+		if !flags.tests && strings.HasSuffix(pkg.Name, "_test") || !flags.commands && len(primary) > 1 && pkg.Name == "main" {
+			// skip embedded non-API packages
+			continue
+		}
+		if pkg.Name == "main" {
+			a.commands[dir] = struct{}{}
+		}
+		ast.Walk(
+			visitor{
+				analyzer: a,
+				pkg:      pkg,
+			},
+			pkg,
+		)
+	}
+}
+
+// strict reports, with -strict, the failure of any directory to parse completely.
+func (a *analyzer) strict() error {
+	if !flags.strict || len(a.failures) == 0 {
+		return nil
+	}
+	var dirs []string
+	for dir := range a.failures {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return gocore.Error("strict", ErrLoadFailed, map[string]string{
+		"failures":    strconv.Itoa(len(dirs)),
+		"directories": strings.Join(dirs, ","),
+	})
+}
+
+// parsefiles parses the go files of a directory by package name, as parser.ParseDir, but
+// retains the partial syntax of the files with errors, returning the first error.
+func (a *analyzer) parsefiles(dir string) (map[string]*ast.Package, error) {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := map[string]*ast.Package{}
+	var first error
+	for _, ent := range ents {
+		name := ent.Name()
+		if ent.IsDir() || !strings.HasSuffix(name, ".go") ||
+			!flags.tests && strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		pth := filepath.Join(dir, name)
+		file, err := parser.ParseFile(a.fset, pth, nil, parser.ParseComments) // read comments for go:build constraints
+		if err != nil && first == nil {
+			first = err
+		}
+		if file == nil || file.Name == nil || file.Name.Name == "_" {
+			continue
+		}
+		pkg, ok := pkgs[file.Name.Name]
+		if !ok {
+			pkg = &ast.Package{Name: file.Name.Name, Files: map[string]*ast.File{}}
+			pkgs[pkg.Name] = pkg
+		}
+		pkg.Files[pth] = file
+	}
+	return pkgs, first
+}
+
+// declname reads the name of a package from the package clauses of its directory's files,
+// as it may differ from the last element of the import path, e.g. package yaml of import
+// gopkg.in/yaml.v3 or package a of directory a-go. The first file that satisfies the build
+// constraints declares it; without one, the name is inferred from the import path.
+func (a *analyzer) declname(abs, pth string) string {
+	if name, ok := a.pkgnames[abs]; ok {
+		return name
+	}
+	name := pkgname(pth)
+	a.pkgnames[abs] = name
+
+	dir := abs
+	if _, err := gocore.Subdir(dirimps, abs); err == nil {
+		if dir = verspath(abs); dir == "" {
+			return name
+		}
+	}
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return name
+	}
+	for _, ent := range ents {
+		base := ent.Name()
+		if ent.IsDir() || !strings.HasSuffix(base, ".go") || strings.HasSuffix(base, "_test.go") {
+			continue
+		}
+		pth := filepath.Join(dir, base)
+		file, err := parser.ParseFile(token.NewFileSet(), pth, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || file.Name == nil || file.Name.Name == "_" || !evaluate(pth, file).keep {
+			continue
+		}
+		a.pkgnames[abs] = file.Name.Name
+		return file.Name.Name
+	}
+	return name
+}
+
+// parsedir parses the go files of a directory. If parsing exceeds the -timeout-per-package,
+// the directory is skipped and its node marked as incomplete. The abandoned parse completes
+// in the background, as the parser cannot be cancelled.
+func (a *analyzer) parsedir(ctx context.Context, dir string) (map[string]*ast.Package, error) {
+	parse := func() (map[string]*ast.Package, error) {
+		return a.parsefiles(dir)
+	}
+
+	if flags.timeoutPerPackage <= 0 {
+		return parse()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, flags.timeoutPerPackage)
+	defer cancel()
+
+	done := make(chan parsed, 1)
+	go func() {
+		pkgs, err := parse()
+		done <- parsed{pkgs: pkgs, err: err}
+	}()
+
+	select {
+	case p := <-done:
+		return p.pkgs, p.err
+	case <-ctx.Done():
+		err := gocore.Error("parse", ctx.Err(), map[string]string{
+			"directory": dir,
+			"timeout":   flags.timeoutPerPackage.String(),
+		})
+		err.Warn()
+		return nil, err
+	}
+}
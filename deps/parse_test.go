@@ -0,0 +1,75 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSkipped(t *testing.T) {
+	for _, test := range []struct {
+		pth  string
+		skip string
+	}{
+		{"example.com/m/internal", "internal"},
+		{"example.com/m/internal/x", "internal"},
+		{"internal/abi", "internal"},
+		{"example.com/m/x/testdata", "testdata"},
+		{"example.com/m/internals", ""},
+		{"example.com/m/internalize", ""},
+		{"example.com/internalize/x", ""},
+		{"example.com/m/mytestdata/x", ""},
+		{"example.com/m", ""},
+	} {
+		t.Run(test.pth, func(t *testing.T) {
+			skip, ok := newanalyzer().skipped(test.pth)
+			if skip != test.skip || ok != (test.skip != "") {
+				t.Errorf("skipped(%q) = %q, %t, want %q, %t", test.pth, skip, ok, test.skip, test.skip != "")
+			}
+		})
+	}
+}
+
+func TestUnparsedFixture(t *testing.T) {
+	r := analyze(t, "graph", Options{}) // the fixture's directory is within testdata
+	if _, ok := r.Trees["DEFINES"]["b.Do"]; !ok {
+		t.Errorf("DEFINES lacks b.Do of the module in testdata: %v", r.Trees["DEFINES"])
+	}
+}
+
+func TestDeclname(t *testing.T) {
+	dir := fixture(t, "imports")
+	for _, test := range []struct {
+		dir  string
+		pth  string
+		name string
+	}{
+		{"e-go", "example.com/imports/e-go", "e"},
+		{"a", "example.com/imports/a", "a"},
+		{"yaml.v3", "gopkg.in/yaml.v3", "yaml"}, // inferred, as the directory is absent
+		{"v2", "example.com/imports/z/v2", "z"}, // inferred, skipping the major version
+	} {
+		t.Run(test.pth, func(t *testing.T) {
+			if name := newanalyzer().declname(filepath.Join(dir, test.dir), test.pth); name != test.name {
+				t.Errorf("declname(%s) = %s, want %s", test.pth, name, test.name)
+			}
+		})
+	}
+}
+
+func TestAddImpDeclaredName(t *testing.T) {
+	r := analyze(t, "imports", Options{})
+	dir := fixture(t, "imports")
+	if _, ok := r.Trees["IMPORTS"]["e"][filepath.Join(dir, "e-go")]; !ok {
+		t.Errorf("IMPORTS = %v, want package e of e-go", r.Trees["IMPORTS"])
+	}
+	for _, from := range []string{
+		"b", // imported without an alias
+		"f", // imported with the alias y
+	} {
+		if !referenced(r, dir, "e.G", from, "e-go") {
+			t.Errorf("REFERENCES e.G = %v, want from %s to e-go", r.Trees["REFERENCES"]["e.G"], from)
+		}
+	}
+}
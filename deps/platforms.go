@@ -0,0 +1,105 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+)
+
+type (
+	// platformed is an import or a dependency with the platforms on which it applies.
+	platformed struct {
+		Package   string   `json:"package,omitempty"`
+		Directory string   `json:"directory,omitempty"`
+		From      string   `json:"from,omitempty"`
+		To        string   `json:"to,omitempty"`
+		Platforms []string `json:"platforms"`
+	}
+)
+
+// platforms analyzes the module for each of the -platforms, i.e. GOOS or GOOS/GOARCH
+// targets, and writes one JSON object with the imports and the package dependencies,
+// each with the platforms on which it applies. With -commands, these are only those of
+// the packages that the module's commands reach.
+func platforms(ctx context.Context, w io.Writer) error {
+	targets := strings.Split(flags.platforms, ",")
+	imports := map[[2]string][]string{} // {package, directory}:platforms
+	deps := map[[2]string][]string{}    // {from, to}:platforms
+	for _, target := range targets {
+		o := flags // the options of the command, e.g. -skip, -tests, and -commands, for the target
+		var goarch string
+		o.goos, goarch, _ = strings.Cut(target, "/")
+		if goarch != "" {
+			o.goarch = goarch
+		}
+		a, err := analysis(ctx, cwd, o)
+		if err != nil {
+			return err
+		}
+		reached := func(string) bool { return true }
+		if flags.commands { // only the packages that the module's commands reach
+			lks := links(a.refs)
+			roots := a.commandroots()
+			lks.closure(roots)
+			dirs := map[string]struct{}{}
+			for _, dir := range roots {
+				dirs[dir] = struct{}{}
+			}
+			for lk := range lks {
+				dirs[lk.from], dirs[lk.to] = struct{}{}, struct{}{}
+			}
+			reached = func(dir string) bool {
+				_, ok := dirs[dir]
+				return ok
+			}
+		}
+		for pkg, dirs := range a.trees[IMPORTS] {
+			for dir := range dirs {
+				if !reached(dir) {
+					continue
+				}
+				key := [2]string{pkg, dir}
+				imports[key] = append(imports[key], target)
+			}
+		}
+		for _, rdirs := range a.trees[REFERENCES] {
+			for rdir, ddirs := range rdirs {
+				if !reached(rdir) {
+					continue
+				}
+				for ddir := range ddirs {
+					if key := [2]string{importpath(rdir), importpath(ddir)}; key[0] != key[1] {
+						if pts := deps[key]; len(pts) == 0 || pts[len(pts)-1] != target {
+							deps[key] = append(pts, target)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	obj := struct {
+		Platforms    []string     `json:"platforms"`
+		Imports      []platformed `json:"imports"`
+		Dependencies []platformed `json:"dependencies"`
+	}{Platforms: targets}
+	for key, pts := range imports {
+		obj.Imports = append(obj.Imports, platformed{Package: key[0], Directory: key[1], Platforms: pts})
+	}
+	for key, pts := range deps {
+		obj.Dependencies = append(obj.Dependencies, platformed{From: key[0], To: key[1], Platforms: pts})
+	}
+	sort.Slice(obj.Imports, func(i, j int) bool {
+		return obj.Imports[i].Package < obj.Imports[j].Package ||
+			obj.Imports[i].Package == obj.Imports[j].Package && obj.Imports[i].Directory < obj.Imports[j].Directory
+	})
+	sort.Slice(obj.Dependencies, func(i, j int) bool {
+		return obj.Dependencies[i].From < obj.Dependencies[j].From ||
+			obj.Dependencies[i].From == obj.Dependencies[j].From && obj.Dependencies[i].To < obj.Dependencies[j].To
+	})
+
+	return encoder(w).Encode(obj)
+}
@@ -0,0 +1,73 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlatforms(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		commands bool
+		want     map[string][]string // dependency:platforms
+	}{
+		{"module", false, map[string][]string{
+			".:u":        {"linux", "windows"},
+			"cmd/tool:x": {"linux", "windows"},
+			"x:lin":      {"linux"},
+			"x:win":      {"windows"},
+			"x:tst":      {"linux", "windows"},
+			"x:testing":  {"linux", "windows"},
+		}},
+		{"commands", true, map[string][]string{
+			"cmd/tool:x": {"linux", "windows"},
+			"x:lin":      {"linux"},
+			"x:win":      {"windows"},
+			"x:tst":      {"linux", "windows"},
+			"x:testing":  {"linux", "windows"},
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			out, _, err := run(t, "platform", func() {
+				flags.json, flags.platforms = true, "linux,windows"
+				flags.skip, flags.tests, flags.commands = "y", true, test.commands
+			})
+			if err != nil {
+				t.Fatalf("Main() error = %v", err)
+			}
+			var obj struct {
+				Dependencies []platformed `json:"dependencies"`
+			}
+			if err := json.Unmarshal([]byte(out), &obj); err != nil {
+				t.Fatalf("Main() JSON error = %v:\n%s", err, out)
+			}
+
+			rel := func(pth string) string {
+				if pth == "example.com/platform" {
+					return "."
+				}
+				return strings.TrimPrefix(pth, "example.com/platform/")
+			}
+			got := map[string][]string{}
+			for _, dep := range obj.Dependencies {
+				if strings.HasPrefix(dep.From, "example.com/platform") {
+					got[rel(dep.From)+":"+rel(dep.To)] = dep.Platforms
+				}
+			}
+			for dep, pts := range test.want {
+				if !slices.Equal(got[dep], pts) {
+					t.Errorf("-platforms dependency %s on %v, want %v", dep, got[dep], pts)
+				}
+			}
+			for dep := range got {
+				if _, ok := test.want[dep]; !ok {
+					t.Errorf("-platforms dependency %s on %v, want none", dep, got[dep])
+				}
+			}
+		})
+	}
+}
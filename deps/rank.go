@@ -0,0 +1,55 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"math"
+)
+
+// rank computes the -sort metric, the count of distinct dependent (fanin) or
+// dependency (fanout) packages, of each package of the dependencies.
+func (a *analyzer) rank(lks linkset) {
+	for lk := range lks {
+		if lk.from == lk.to {
+			continue
+		}
+		switch flags.sort {
+		case "fanin":
+			a.ranks[lk.to]++
+		case "fanout":
+			a.ranks[lk.from]++
+		}
+	}
+}
+
+// arrangement returns the table and order for traversing a tree. The top level nodes of
+// the IMPORTS and REFERENCES trees are ordered by descending -sort metric of their packages,
+// i.e. of the imported package or of the package defining the referenced identifier.
+func (a *analyzer) arrangement(t TREE) (table, func(string, table) string) {
+	if flags.sort == "name" || t != IMPORTS && t != REFERENCES {
+		return nil, canonicalize
+	}
+
+	tbl := table{}
+	for node, children := range a.trees[t] {
+		n := 0
+		for child, grandchildren := range children {
+			if t == IMPORTS {
+				n = max(n, a.ranks[child])
+				continue
+			}
+			for dir := range grandchildren {
+				n = max(n, a.ranks[dir])
+			}
+		}
+		tbl[node] = n
+	}
+
+	return tbl, func(node string, tbl table) string {
+		if n, ok := tbl[node].(int); ok {
+			return fmt.Sprintf("%010d %s", math.MaxInt32-n, canonicalize(node, tbl))
+		}
+		return canonicalize(node, tbl)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// semverimpact recommends the semantic version bump of the module's exported API relative to
+// a baseline checkout of the module: major if a symbol or declaration, e.g. a signature, was
+// removed or a method was added to an existing interface, minor if only symbols or declarations
+// were added, and patch if the API is unchanged. It lists the changes driving the bump.
+func semverimpact(ctx context.Context, w io.Writer, baseline string) error {
+	base, err := analyzed(ctx, baseline)
+	if err != nil {
+		return err
+	}
+	head, err := analyzed(ctx, cwd)
+	if err != nil {
+		return err
+	}
+
+	var major, minor []string
+	for _, line := range difference(base.api, head.api) {
+		entry := line[2:]
+		t, rest, _ := strings.Cut(entry, " ")
+		ifc, _, method := strings.Cut(rest, " ")
+		_, existing := base.api[t+" "+ifc]
+		switch {
+		case line[0] == '-',
+			t == names[INTERFACES] && method && existing: // breaks the interface's implementations
+			major = append(major, line)
+		default:
+			minor = append(minor, line)
+		}
+	}
+
+	bump, lines := "patch", []string(nil)
+	if len(major) > 0 {
+		bump, lines = "major", major
+	} else if len(minor) > 0 {
+		bump, lines = "minor", minor
+	}
+	fmt.Fprintln(w, "==== SEMVER IMPACT ====")
+	fmt.Fprintln(w, bump)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
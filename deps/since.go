@@ -0,0 +1,94 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"go/token"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// recent is the GraphTransformer that highlights the dependencies introduced after a date,
+	// according to git blame of the lines of the dependencies' originating references.
+	recent struct {
+		since   time.Time
+		origins map[string]map[string]token.Position // the analysis's first references
+		blames  map[string][]time.Time               // file:time of each line, from 1
+	}
+)
+
+// parsesince interprets the -since date, as a date or an RFC 3339 time.
+func parsesince(s string) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, gocore.Error("since", errors.New("invalid date"), map[string]string{
+		"since": s,
+		"valid": "YYYY-MM-DD or RFC 3339",
+	})
+}
+
+// Transform highlights each dependency whose references were all introduced after the date.
+// A dependency dates from the oldest of the lines of the first reference to each of its symbols.
+func (rc recent) Transform(g *Graph) error {
+	for _, e := range g.Edges {
+		var oldest time.Time
+		for _, sym := range e.Symbols {
+			pos, ok := rc.origins[e.From][sym]
+			if !ok {
+				continue
+			}
+			if t, ok := rc.blame(pos.Filename, pos.Line); ok && (oldest.IsZero() || t.Before(oldest)) {
+				oldest = t
+			}
+		}
+		if !oldest.IsZero() && oldest.After(rc.since) {
+			e.Color = "orange"
+			e.Attrs = emphasize(e.Attrs, "penwidth=3")
+			e.Tooltip += "\\nintroduced " + oldest.Format(time.DateOnly)
+		}
+	}
+	return nil
+}
+
+// blame determines when a line of a file was last changed, running git blame once per file.
+// Uncommitted lines date from now.
+func (rc recent) blame(file string, line int) (time.Time, bool) {
+	times, ok := rc.blames[file]
+	if !ok {
+		cmd := exec.Command("git", "blame", "--line-porcelain", "--", file)
+		cmd.Dir = dirmod
+		stderr := &bytes.Buffer{}
+		cmd.Stderr = stderr
+		out, err := cmd.Output()
+		if err != nil {
+			gocore.Error("git blame", err, map[string]string{
+				"file":   file,
+				"stderr": stderr.String(),
+			}).Warn()
+		}
+		times = []time.Time{{}} // lines number from 1
+		sc := bufio.NewScanner(bytes.NewReader(out))
+		for sc.Scan() {
+			if s, ok := strings.CutPrefix(sc.Text(), "author-time "); ok {
+				secs, _ := strconv.ParseInt(s, 10, 64)
+				times = append(times, time.Unix(secs, 0))
+			}
+		}
+		rc.blames[file] = times
+	}
+	if line < 1 || line >= len(times) {
+		return time.Time{}, false
+	}
+	return times[line], true
+}
@@ -0,0 +1,44 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"html"
+)
+
+// statspanel formats a graphviz node with an HTML-like table label that totals the graph's
+// packages, dependencies, and module package cycles, and names the package with the most
+// dependents. The node is ranked first to place it in the graph's upper left corner.
+func statspanel(g *Graph) string {
+	lks := linkset{}
+	for _, e := range g.Edges {
+		if g.Nodes[e.From] != nil && g.Nodes[e.To] != nil {
+			lks[link{from: e.From, to: e.To}] = tree{}
+		}
+	}
+
+	var top string
+	var n int
+	for dir, count := range fanins(lks) {
+		if pkg := importpath(dir); count > n || count == n && pkg < top {
+			top, n = pkg, count
+		}
+	}
+	if top != "" {
+		top = fmt.Sprintf("%s (%d)", top, n)
+	}
+
+	row := `<tr><td align="left">%s</td><td align="right">%s</td></tr>`
+	return fmt.Sprintf(`
+"Statistics" [shape=plaintext style="" fontcolor=lightgrey label=<<table border="1" cellborder="0" color="lightgrey">`+
+		row+row+row+row+
+		`</table>>]
+{ rank=min "Statistics" }
+`,
+		"packages", fmt.Sprint(len(g.Nodes)),
+		"dependencies", fmt.Sprint(len(lks)),
+		"cycles", fmt.Sprint(len(cycles(lks))),
+		"top fan-in", html.EscapeString(top),
+	)
+}
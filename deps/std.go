@@ -0,0 +1,76 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// nostd is the GraphTransformer that removes the standard packages and the dependencies on them.
+	nostd struct{}
+)
+
+// Transform removes the nodes of the standard packages and their edges.
+func (nostd) Transform(g *Graph) error {
+	for dir, n := range g.Nodes {
+		if n.Cluster == standard {
+			delete(g.Nodes, dir)
+		}
+	}
+	g.Edges = slices.DeleteFunc(g.Edges, func(e *Edge) bool {
+		return g.Nodes[e.From] == nil || g.Nodes[e.To] == nil
+	})
+	return nil
+}
+
+// stdreport lists the standard packages that the module uses, grouped by their top-level
+// category (e.g. crypto, net) with the count of the module's references to each group.
+func stdreport(lks linkset) {
+	type group struct {
+		name string
+		uses int
+		pkgs map[string]struct{}
+	}
+	groups := map[string]*group{}
+	for lk, syms := range lks {
+		if _, err := gocore.Subdir(dirstd, lk.to); err != nil || dirmod == dirstd {
+			continue
+		}
+		pkg := importpath(lk.to)
+		name, _, _ := strings.Cut(pkg, "/")
+		g, ok := groups[name]
+		if !ok {
+			g = &group{name: name, pkgs: map[string]struct{}{}}
+			groups[name] = g
+		}
+		g.pkgs[pkg] = struct{}{}
+		g.uses += max(len(syms), 1) // dependencies from go list have no symbols
+	}
+
+	var sorted []*group
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].uses > sorted[j].uses ||
+			sorted[i].uses == sorted[j].uses && sorted[i].name < sorted[j].name
+	})
+
+	fmt.Fprintln(os.Stderr, "==== STANDARD PACKAGES ====")
+	fmt.Fprintf(os.Stderr, "%6s  %-12s %s\n", "USES", "CATEGORY", "PACKAGES")
+	for _, g := range sorted {
+		var pkgs []string
+		for pkg := range g.pkgs {
+			pkgs = append(pkgs, pkg)
+		}
+		sort.Strings(pkgs)
+		fmt.Fprintf(os.Stderr, "%6d  %-12s %s\n", g.uses, g.name, strings.Join(pkgs, ", "))
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// summary totals the module's packages, their dependencies, and their exported identifiers.
+	summary struct {
+		Packages     int `json:"packages"`
+		Dependencies int `json:"dependencies"`
+		Types        int `json:"types"`
+		Interfaces   int `json:"interfaces"`
+		Functions    int `json:"functions"`
+		Methods      int `json:"methods"`
+		Values       int `json:"values"`
+	}
+)
+
+// summarize totals the module's packages, the dependencies of the module's packages, and
+// the module's exported identifiers by category, i.e. the size of the module's API surface.
+func (a *analyzer) summarize(lks linkset) summary {
+	var s summary
+	for lk := range lks {
+		if _, err := gocore.Subdir(dirmod, lk.from); err == nil && lk.from != lk.to {
+			s.Dependencies++
+		}
+	}
+	for dir, counts := range a.exports {
+		if _, err := gocore.Subdir(dirmod, dir); err != nil {
+			continue
+		}
+		s.Types += counts["types"]
+		s.Interfaces += counts["interfaces"]
+		s.Functions += counts["functions"]
+		s.Methods += counts["methods"]
+		s.Values += counts["values"]
+	}
+	s.Packages = len(a.packages(lks))
+	return s
+}
+
+// packages collects the directories of the module's packages, i.e. those with
+// dependencies or exported identifiers.
+func (a *analyzer) packages(lks linkset) map[string]struct{} {
+	pkgs := map[string]struct{}{}
+	for lk := range lks {
+		if _, err := gocore.Subdir(dirmod, lk.from); err == nil {
+			pkgs[lk.from] = struct{}{}
+		}
+	}
+	for dir := range a.exports {
+		if _, err := gocore.Subdir(dirmod, dir); err == nil {
+			pkgs[dir] = struct{}{}
+		}
+	}
+	return pkgs
+}
+
+// report writes the summary as the last section of the report.
+func (s summary) report(w io.Writer) {
+	fmt.Fprintln(w, "==== SUMMARY ====")
+	fmt.Fprintf(w, "%d packages, %d dependencies\n", s.Packages, s.Dependencies)
+	fmt.Fprintf(w, "exported: %d types, %d interfaces, %d functions, %d methods, %d values\n",
+		s.Types, s.Interfaces, s.Functions, s.Methods, s.Values)
+}
+
+// encode writes the summary as a JSON object.
+func (s summary) encode(w io.Writer) error {
+	return encoder(w).Encode(s)
+}
@@ -0,0 +1,11 @@
+package cgo
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import "fmt"
+
+// Hello greets with fmt.
+func Hello() { fmt.Println("hello") }
@@ -0,0 +1,6 @@
+package a
+
+import "example.com/cycle/b"
+
+// A calls B.
+func A() { b.B() }
@@ -0,0 +1,6 @@
+package b
+
+import "example.com/cycle/a"
+
+// B calls A.
+func B() { a.A() }
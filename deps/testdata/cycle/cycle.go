@@ -0,0 +1,6 @@
+package cycle
+
+import "example.com/cycle/a"
+
+// Run runs a.
+func Run() { a.A() }
@@ -0,0 +1,6 @@
+package doconly
+
+import "fmt"
+
+// Hello greets.
+func Hello() { fmt.Println("hello") }
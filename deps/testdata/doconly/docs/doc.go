@@ -0,0 +1,2 @@
+// Package docs documents the module, and declares nothing.
+package docs
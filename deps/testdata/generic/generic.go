@@ -0,0 +1,21 @@
+package generic
+
+// Sizer sizes.
+type Sizer interface {
+	Len() int
+}
+
+// Pusher pushes ints.
+type Pusher interface {
+	Push(int)
+	Len() int
+}
+
+// Stack is a generic container.
+type Stack[T any] struct{ items []T }
+
+// Push pushes.
+func (s *Stack[T]) Push(t T) { s.items = append(s.items, t) }
+
+// Len sizes.
+func (s *Stack[T]) Len() int { return len(s.items) }
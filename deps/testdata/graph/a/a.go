@@ -0,0 +1,11 @@
+package a
+
+import (
+	"fmt"
+
+	"example.com/graph/b"
+	"example.com/graph/c"
+)
+
+// Run runs.
+func Run() { fmt.Println(b.Do(1), c.Value) }
@@ -0,0 +1,12 @@
+package b
+
+import "example.com/graph/c"
+
+// Thing is a thing.
+type Thing struct{ Name string }
+
+// Do does.
+func Do(n int) int { return n + c.Value }
+
+// String implements Stringer.
+func (t Thing) String() string { return t.Name }
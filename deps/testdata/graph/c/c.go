@@ -0,0 +1,4 @@
+package c
+
+// Value is a value.
+var Value = 42
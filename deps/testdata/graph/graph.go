@@ -0,0 +1,3 @@
+package graph
+
+import _ "example.com/graph/a"
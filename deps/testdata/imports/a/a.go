@@ -0,0 +1,10 @@
+package a
+
+type T struct{ N int }
+
+func F() int { return 1 }
+
+var V = 2
+
+// N is also the name of the field of T.
+var N = 4
@@ -0,0 +1,8 @@
+package b
+
+import (
+	x "example.com/imports/a"
+	"example.com/imports/e-go"
+)
+
+var V = x.F() + x.T{}.N + e.G()
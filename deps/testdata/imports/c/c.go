@@ -0,0 +1,16 @@
+package c
+
+import (
+	. "example.com/imports/a"
+	. "strings"
+)
+
+var V = F() + V2
+
+var V2 = T{N: 1}.N + len(ToUpper("x"))
+
+// S has a method of the name of a function of strings.
+type S struct{}
+
+// ToLower is not the function of strings.
+func (S) ToLower() string { return "" }
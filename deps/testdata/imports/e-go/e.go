@@ -0,0 +1,3 @@
+package e
+
+func G() int { return 3 }
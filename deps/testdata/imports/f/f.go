@@ -0,0 +1,5 @@
+package f
+
+import y "example.com/imports/e-go"
+
+var W = y.G()
@@ -0,0 +1,11 @@
+package imports
+
+import (
+	"example.com/imports/b"
+	"example.com/imports/c"
+	"example.com/imports/f"
+)
+
+var X = b.V + c.V
+
+var _ = f.W
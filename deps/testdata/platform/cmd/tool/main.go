@@ -0,0 +1,5 @@
+package main
+
+import "example.com/platform/x"
+
+func main() { x.X() }
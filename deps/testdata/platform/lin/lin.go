@@ -0,0 +1,4 @@
+package lin
+
+// F does nothing.
+func F() {}
@@ -0,0 +1,6 @@
+package platform
+
+import "example.com/platform/u"
+
+// V is in every build.
+var V = u.U
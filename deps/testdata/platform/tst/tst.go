@@ -0,0 +1,4 @@
+package tst
+
+// T does nothing.
+func T() {}
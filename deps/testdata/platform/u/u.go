@@ -0,0 +1,4 @@
+package u
+
+// U is a constant.
+const U = 1
@@ -0,0 +1,4 @@
+package win
+
+// F does nothing.
+func F() {}
@@ -0,0 +1,2 @@
+// Package x depends on the platform.
+package x
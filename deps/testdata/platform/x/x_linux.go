@@ -0,0 +1,6 @@
+package x
+
+import "example.com/platform/lin"
+
+// X calls lin.
+func X() { lin.F() }
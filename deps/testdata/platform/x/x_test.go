@@ -0,0 +1,9 @@
+package x
+
+import (
+	"testing"
+
+	"example.com/platform/tst"
+)
+
+func TestX(t *testing.T) { tst.T() }
@@ -0,0 +1,6 @@
+package x
+
+import "example.com/platform/win"
+
+// X calls win.
+func X() { win.F() }
@@ -0,0 +1,6 @@
+package y
+
+import "example.com/platform/u"
+
+// Y is skipped.
+var Y = u.U
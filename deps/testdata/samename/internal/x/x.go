@@ -0,0 +1,9 @@
+package x
+
+import ox "example.com/samename/x"
+
+// Sink of the same name, which godep skips, writes a Buffer.
+type Sink struct{}
+
+// Write writes a Buffer.
+func (Sink) Write(ox.Buffer) error { return nil }
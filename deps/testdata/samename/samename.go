@@ -0,0 +1,3 @@
+package samename
+
+import _ "example.com/samename/x"
@@ -0,0 +1,15 @@
+package x
+
+// Buffer is written.
+type Buffer struct{}
+
+// Writer writes a Buffer.
+type Writer interface {
+	Write(Buffer) error
+}
+
+// Sink writes bytes rather than a Buffer.
+type Sink struct{}
+
+// Write writes bytes.
+func (Sink) Write([]byte) error { return nil }
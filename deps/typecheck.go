@@ -0,0 +1,117 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"context"
+	"go/types"
+	"os"
+	"path/filepath"
+
+	"github.com/zosmac/gocore"
+	"golang.org/x/tools/go/packages"
+)
+
+// typechecking reports whether typesets loads the type information to decide the IMPLEMENTS
+// tree, as it does for every output. A module zip is extracted without the modules that it
+// requires, so the go command cannot load its packages.
+func typechecking() bool {
+	return flags.zip == ""
+}
+
+// typecheck loads the type information of the module's packages and their dependencies, as the
+// go command builds them for the -goos, -goarch, and -tags, and maps the directories of the
+// packages to the named types that they define, by name. Packages of the same name define
+// types of the same qualified name, so typesets finds a name's types by the directories that
+// DEFINES records for it. If the packages fail to load, the map is empty and typesets compares
+// the method signatures.
+func typecheck(ctx context.Context) map[string]map[string]types.Type {
+	env := append(os.Environ(), "GOOS="+flags.goos, "GOARCH="+flags.goarch)
+	if !flags.cgo {
+		env = append(env, "CGO_ENABLED=0")
+	}
+	var build []string
+	if flags.tags != "" {
+		build = append(build, "-tags="+flags.tags)
+	}
+	pkgs, err := packages.Load(
+		&packages.Config{
+			Mode:       packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes,
+			Context:    ctx,
+			Dir:        dirmod,
+			Env:        env,
+			BuildFlags: build,
+			Tests:      flags.tests,
+		},
+		"./...",
+	)
+	if err != nil {
+		gocore.Error("typecheck", err, map[string]string{
+			"directory": dirmod,
+		}).Warn()
+		return nil
+	}
+
+	checked := map[string]map[string]types.Type{}
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Types == nil || pkg.IllTyped || pkg.Dir == "" { // incomplete type information may mislead
+			return
+		}
+		dir := unversion(filepath.ToSlash(pkg.Dir))
+		if _, ok := checked[dir]; ok { // a package's test variant redefines its types
+			return
+		}
+		checked[dir] = map[string]types.Type{}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			if obj, ok := scope.Lookup(name).(*types.TypeName); ok && obj.Exported() {
+				checked[dir][name] = obj.Type()
+			}
+		}
+	})
+	return checked
+}
+
+// lookup finds the types of a qualified name that the packages of its defining directories define.
+func (a *analyzer) lookup(checked map[string]map[string]types.Type, id string) []types.Type {
+	_, name := qualified(id)
+	var typs []types.Type
+	for dir := range a.defs[id] {
+		if typ, ok := checked[dir][name]; ok {
+			typs = append(typs, typ)
+		}
+	}
+	return typs
+}
+
+// implements reports whether any of the types of a name, or pointers to them, implement any
+// of the interfaces of a name, and whether the type checker could decide it. Generic types and
+// interfaces are undecided, as only their instantiations implement or are implemented.
+func implements(typs, ifcs []types.Type) (bool, bool) {
+	var decided bool
+	for _, ifc := range ifcs {
+		iface, ok := ifc.Underlying().(*types.Interface)
+		if !ok || generic(ifc) {
+			continue
+		}
+		for _, typ := range typs {
+			if generic(typ) {
+				continue
+			}
+			if _, ok := typ.Underlying().(*types.Interface); ok {
+				continue
+			}
+			decided = true
+			if types.Implements(typ, iface) || types.Implements(types.NewPointer(typ), iface) {
+				return true, true
+			}
+		}
+	}
+	return false, decided
+}
+
+// generic reports whether a named type has type parameters.
+func generic(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.TypeParams().Len() > 0
+}
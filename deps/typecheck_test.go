@@ -0,0 +1,56 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTypesetsGeneric(t *testing.T) {
+	sets := analyze(t, "generic", Options{}).Trees["IMPLEMENTS"]
+	if _, ok := sets["generic.Sizer"]["generic.Stack"]["Len() int"]; !ok {
+		t.Errorf("IMPLEMENTS generic.Sizer = %v, want generic.Stack by Len() int", sets["generic.Sizer"])
+	}
+	if _, ok := sets["generic.Pusher"]["generic.Stack"]; ok {
+		t.Errorf("IMPLEMENTS generic.Pusher includes generic.Stack, whose Push(T) is not Push(int)")
+	}
+}
+
+func TestTypesetsSameName(t *testing.T) {
+	sets := analyze(t, "samename", Options{}).Trees["IMPLEMENTS"]
+	if _, ok := sets["x.Writer"]["x.Sink"]; ok {
+		t.Errorf("IMPLEMENTS x.Writer includes x.Sink, whose Write([]byte) is not Write(Buffer)")
+	}
+}
+
+func TestTypecheckJSON(t *testing.T) {
+	out, _, err := run(t, "samename", func() { flags.json = true })
+	if err != nil {
+		t.Fatalf("Main() error = %v", err)
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(out), &obj); err != nil {
+		t.Fatalf("Main() JSON error = %v:\n%s", err, out)
+	}
+	var sets tree
+	if err := json.Unmarshal(obj["IMPLEMENTS"], &sets); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sets["x.Writer"]["x.Sink"]; ok {
+		t.Errorf("-json IMPLEMENTS x.Writer includes x.Sink, whose Write([]byte) is not Write(Buffer)")
+	}
+	if checked, ok := obj["TYPECHECKED"]; ok {
+		t.Errorf("-json TYPECHECKED = %s, want none", checked)
+	}
+
+	var buf bytes.Buffer
+	if err := newanalyzer().encode(&buf, linkset{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"TYPECHECKED":false`) {
+		t.Errorf("encode() without type checking lacks TYPECHECKED false:\n%s", buf.String())
+	}
+}
@@ -2,7 +2,7 @@
 
 //go:build ignore
 
-package main
+package deps
 
 import (
 	"fmt"
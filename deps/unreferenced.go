@@ -0,0 +1,28 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// unreferenced reports the module's packages that no other module package depends on, other
+// than main packages, which are expected to have no dependents. These are candidates for
+// removal, or are API consumed only by other modules.
+func (a *analyzer) unreferenced(lks linkset) {
+	counts := fanins(lks)
+	var pkgs []string
+	for dir := range a.packages(lks) {
+		if _, ok := a.commands[dir]; !ok && counts[dir] == 0 {
+			pkgs = append(pkgs, importpath(dir))
+		}
+	}
+	sort.Strings(pkgs)
+
+	fmt.Fprintln(os.Stderr, "==== UNREFERENCED PACKAGES ====")
+	for _, pkg := range pkgs {
+		fmt.Fprintln(os.Stderr, pkg)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/zosmac/gocore"
+)
+
+// unused reports the imports of the module's packages that the package references at most
+// once, as candidates for removal or inlining. Blank imports are imported for their side
+// effects, and the references through dot imports are unqualified, so neither is counted.
+func (a *analyzer) unused() {
+	type candidate struct {
+		pkg  string
+		pth  string
+		note string
+		uses int
+	}
+	var candidates []candidate
+	for dir, imps := range a.usage {
+		if _, err := gocore.Subdir(dirmod, dir); err != nil {
+			continue
+		}
+		for pth, imp := range imps {
+			c := candidate{pkg: importpath(dir), pth: pth, uses: imp.uses}
+			switch imp.alias {
+			case "_":
+				c.note = " (blank)"
+			case ".":
+				c.note = " (dot, uncounted)"
+			default:
+				if imp.uses > 1 {
+					continue
+				}
+			}
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].pkg < candidates[j].pkg ||
+			candidates[i].pkg == candidates[j].pkg && candidates[i].pth < candidates[j].pth
+	})
+
+	fmt.Fprintln(os.Stderr, "==== UNUSED IMPORTS ====")
+	fmt.Fprintf(os.Stderr, "%4s  %-40s %s\n", "USES", "PACKAGE", "IMPORT")
+	for _, c := range candidates {
+		fmt.Fprintf(os.Stderr, "%4d  %-40s %s%s\n", c.uses, c.pkg, c.pth, c.note)
+	}
+}
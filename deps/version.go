@@ -0,0 +1,182 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"cmp"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zosmac/gocore"
+)
+
+// verspath checks if import path references a versioned name (i.e. @vn.n.n), selecting
+// the version that the module's go.mod requires, or absent a requirement, the latest.
+func verspath(pth string) string {
+	var rem string
+	for {
+		dir := path.Dir(pth)
+		base := path.Base(pth)
+		if dir == dirimps {
+			return ""
+		}
+		if ents, err := os.ReadDir(dir); err == nil {
+			var vers []string
+			for _, ent := range ents {
+				if b, a, ok := strings.Cut(ent.Name(), "@"); ok && b == base {
+					vers = append(vers, a) // versioned directories for package
+				}
+			}
+			if len(vers) > 0 {
+				v := latest(vers)
+				if rel, err := gocore.Subdir(dirimps, pth); err == nil {
+					if req, ok := required()[unescape(filepath.ToSlash(rel))]; ok && slices.Contains(vers, req) {
+						v = req
+					}
+				}
+				return path.Join(dir, base+"@"+v, rem)
+			}
+		}
+		pth = dir                  // check the next level up
+		rem = path.Join(base, rem) // keep remaining subdirectories together
+	}
+}
+
+// required reads, once per located module, the require directives of the module's go.mod.
+var required = sync.OnceValue(requires)
+
+// requires reads the module paths and versions of the require directives of the module's go.mod.
+func requires() map[string]string {
+	reqs := map[string]string{}
+	data, err := os.ReadFile(filepath.Join(dirmod, "go.mod"))
+	if err != nil {
+		return reqs
+	}
+	var block bool
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case block && fields[0] == ")":
+			block = false
+		case block && len(fields) == 2:
+			reqs[fields[0]] = fields[1]
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			block = true
+		case fields[0] == "require" && len(fields) == 3:
+			reqs[fields[1]] = fields[2]
+		}
+	}
+	return reqs
+}
+
+// latest selects the highest of the versions by semantic version precedence.
+func latest(vers []string) string {
+	v := vers[0]
+	for _, ver := range vers[1:] {
+		if semver(ver, v) > 0 {
+			v = ver
+		}
+	}
+	return v
+}
+
+// semver compares two versions by semantic version precedence, e.g. v1.10.0 follows v1.9.0,
+// and a release follows its pre-releases. Build metadata, e.g. +incompatible, is ignored.
+func semver(a, b string) int {
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "+")
+	ar, apre, _ := strings.Cut(a, "-")
+	br, bpre, _ := strings.Cut(b, "-")
+	if c := segments(ar, br); c != 0 {
+		return c
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	}
+	return segments(apre, bpre)
+}
+
+// segments compares dot separated version identifiers, numerically when both are numbers.
+func segments(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range min(len(as), len(bs)) {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+		var c int
+		switch {
+		case aerr == nil && berr == nil:
+			c = cmp.Compare(an, bn)
+		case aerr == nil:
+			c = -1 // numeric identifiers precede alphanumeric
+		case berr == nil:
+			c = 1
+		default:
+			c = cmp.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+// unversion strips the @version suffix that the module cache adds to a module's directory.
+// A package's identity is its unversioned directory; only the labels of the nodes of
+// imported packages show their modules' versions, and only with -versions.
+func unversion(pth string) string {
+	if b, a, ok := strings.Cut(pth, "@"); ok { // strip version
+		if _, a, ok := strings.Cut(a, "/"); ok { // reassemble path
+			return path.Join(b, a)
+		}
+		return b
+	}
+	return pth
+}
+
+// version reports the version of the module in the module cache containing a package directory.
+func version(dir string) string {
+	if _, err := gocore.Subdir(dirimps, dir); err != nil {
+		return "" // e.g. vendored
+	}
+	if _, a, ok := strings.Cut(verspath(dir), "@"); ok {
+		v, _, _ := strings.Cut(a, "/")
+		return v
+	}
+	return ""
+}
+
+// pkgname derives the default package name from an import path, omitting
+// a major version element (e.g. /v2) or suffix (e.g. gopkg.in/yaml.v3).
+func pkgname(pth string) string {
+	base := path.Base(pth)
+	if major(base) && path.Dir(pth) != "." {
+		base = path.Base(path.Dir(pth))
+	}
+	name, _, _ := strings.Cut(base, ".") // if package name has ".", strip following (i.e. version)
+	return name
+}
+
+// major reports whether an import path element is a major version, e.g. v2.
+func major(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	for _, c := range elem[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
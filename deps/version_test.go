@@ -0,0 +1,76 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSemver(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		want int
+	}{
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.9.0", "v1.10.0", -1},
+		{"v1.2.0", "v1.2.0", 0},
+		{"v1.2.0", "v1.2.0-rc.1", 1},
+		{"v1.2.0-rc.2", "v1.2.0-rc.10", -1},
+		{"v1.2.0-alpha", "v1.2.0-1", 1},
+		{"v2.0.0+incompatible", "v2.0.0", 0},
+	} {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			if c := semver(test.a, test.b); c != test.want {
+				t.Errorf("semver(%s, %s) = %d, want %d", test.a, test.b, c, test.want)
+			}
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	if v := latest([]string{"v1.2.0", "v1.10.0", "v1.9.0"}); v != "v1.10.0" {
+		t.Errorf("latest() = %s, want v1.10.0", v)
+	}
+}
+
+func TestVerspath(t *testing.T) {
+	imps, mod, req := dirimps, dirmod, required
+	defer func() { dirimps, dirmod, required = imps, mod, req }()
+
+	tmp := filepath.ToSlash(t.TempDir())
+	dirimps, dirmod = path.Join(tmp, "mod"), path.Join(tmp, "app")
+	for _, v := range []string{"v1.2.0", "v1.9.0", "v1.10.0"} {
+		if err := os.MkdirAll(path.Join(dirimps, "example.com", "lib@"+v, "pkg"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(dirmod, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name  string
+		gomod string
+		want  string
+	}{
+		{"latest", "module example.com/app\n", "v1.10.0"},
+		{"required", "module example.com/app\n\nrequire example.com/lib v1.2.0\n", "v1.2.0"},
+		{"required in block", "module example.com/app\n\nrequire (\n\texample.com/lib v1.9.0 // indirect\n)\n", "v1.9.0"},
+		{"required but absent", "module example.com/app\n\nrequire example.com/lib v1.11.0\n", "v1.10.0"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if err := os.WriteFile(path.Join(dirmod, "go.mod"), []byte(test.gomod), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			required = sync.OnceValue(requires)
+			want := path.Join(dirimps, "example.com", "lib@"+test.want, "pkg")
+			if pth := verspath(path.Join(dirimps, "example.com", "lib", "pkg")); pth != want {
+				t.Errorf("verspath() = %s, want %s", pth, want)
+			}
+		})
+	}
+}
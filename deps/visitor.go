@@ -0,0 +1,818 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+type (
+	// analyzer owns the state of an analysis: the trees of the information types parsed from
+	// packages and the records of the directories and files parsed. A fresh analyzer per run
+	// begins with clean state.
+	analyzer struct {
+		// trees anchors all of the information types parsed from packages.
+		trees []tree
+
+		// imps tree reports all imported packages.
+		imps tree
+
+		// ifcs tree reports all interfaces.
+		ifcs tree
+
+		// typs tree reports all exported types. A type alias has a single "= type" entry.
+		typs tree
+
+		// vals tree reports all exported values.
+		vals tree
+
+		// fncs tree reports all exported functions.
+		fncs tree
+
+		// defs tree reports where types, values, and functions are defined.
+		defs tree
+
+		// refs tree reports where types, values, and functions are referenced.
+		refs tree
+
+		// sets tree reports interfaces with types whose method sets comply, and the methods that satisfy each.
+		sets tree
+
+		// builds records for each parsed file its build constraint evaluation.
+		builds map[string]built
+
+		// files records for each package directory the files that contribute to the package.
+		files map[string]map[string]struct{}
+
+		// exports counts for each package directory its exported identifiers by category.
+		exports map[string]map[string]int
+
+		// generics identifies the types with type parameters.
+		generics map[string]struct{}
+
+		// origins records for each package directory the first position referencing each identifier.
+		origins map[string]map[string]token.Position
+
+		// counts counts the references of each identifier of an import by referencing package directory, keyed "directory identifier".
+		counts map[string]int
+
+		// usage counts the references within each package directory to each of its imports, by import path.
+		usage map[string]map[string]*imported
+
+		// cohesion counts the references within each package to its own package level declarations.
+		cohesion map[string]int
+
+		// positions records with -positions the source positions "file:line:column" of each
+		// identifier's definitions and references by package directory, for DEFINES and REFERENCES.
+		positions map[TREE]tree
+
+		// doconly identifies the package directories whose files declare nothing, e.g. only a doc.go.
+		doconly map[string]struct{}
+
+		// production records with -tests for each package directory the identifiers referenced by its non-test files.
+		production map[string]map[string]struct{}
+
+		// failures records the directories that failed to parse completely, with their errors.
+		failures map[string]error
+
+		// parseDirs records that a directory has been parsed.
+		parsedDirs map[string]struct{}
+
+		// commands records the directories of main packages.
+		commands map[string]struct{}
+
+		// pkgnames caches the package names that the files of imported package directories declare.
+		pkgnames map[string]string
+
+		// dotted records by directory the references of bare identifiers to dot imported packages,
+		// which defs4refs keeps only for the package that defines the identifier.
+		dotted map[string]struct{}
+
+		// incomplete reports that the analysis was cut short and the graph is partial.
+		incomplete bool
+
+		// typechecked reports that the type checker decided the IMPLEMENTS tree.
+		typechecked bool
+
+		// highlights maps package directories to the graphviz attributes that emphasize their nodes.
+		highlights map[string]string
+
+		// palette assigns colors to nodes to override their hashed colors.
+		palette map[string]string
+
+		// apex is the package directory whose node is ranked first, i.e. leftmost.
+		apex string
+
+		// ranks maps package directories to the -sort metric of the package.
+		ranks map[string]int
+
+		// violations counts the enforcement check failures of packages that are not exempt.
+		violations int
+
+		// transformers are the GraphTransformers that the flags add to the serializations.
+		transformers []GraphTransformer
+
+		// fset records the positions of the files that the analysis parses.
+		fset *token.FileSet
+
+		// skips identifies the directories to ignore for parsing: skipdirs and those of -skip.
+		skips map[string]struct{}
+	}
+
+	// visitor employed by the AST walk of the parse function.
+	visitor struct {
+		*analyzer
+		pkg   *ast.Package
+		qual  string              // qualifier of the package's identifiers
+		decls map[string]struct{} // package level declarations
+
+		// aliases map selection names used in a file to the imported package names.
+		aliases map[string]string // alias:package
+
+		// importing maps selection names used in a file to the import paths of the imported packages.
+		importing map[string]string // alias:path
+
+		// dots maps the packages that a file imports with . to their import paths.
+		dots map[string]string // package:path
+
+		// unresolved identifies the identifiers that the scopes of a file do not declare, which
+		// excludes the keys of composite literals, e.g. field names, and the names of methods.
+		unresolved map[*ast.Ident]struct{}
+	}
+
+	// imported counts the references within a package to one of its imports.
+	imported struct {
+		alias string // selection name, or _ for a blank or . for a dot import
+		uses  int
+	}
+
+	// built records a file's build constraint and whether the file is parsed.
+	built struct {
+		constraint string // go:build line, file name suffixes, or import "C" when cgo is disabled
+		keep       bool
+	}
+
+	// table maps tree nodes to their data.
+	table = gocore.Table[string, any]
+
+	// tree organizes information types parsed from packages.
+	tree = gocore.Tree[string, string, any]
+
+	// TREE is the enumberation type for information types parsed from packages.
+	TREE int
+)
+
+const (
+	IMPORTS TREE = iota
+	INTERFACES
+	TYPES
+	VALUES
+	FUNCTIONS
+	DEFINES
+	REFERENCES
+	IMPLEMENTS
+	TREES
+)
+
+var (
+	// dirstd is the location of the Go standard packages source.
+	dirstd = path.Join(build.Default.GOROOT, "src")
+
+	// dirimps is the location of the source for imported Go packages.
+	dirimps = path.Join(build.Default.GOPATH, "pkg", "mod")
+
+	// gomod, dirmod are the import path and directory location of the module.
+	gomod, dirmod string
+
+	// names labels each of the information types parsed from packages.
+	names = map[TREE]string{
+		IMPORTS:    "IMPORTS",
+		INTERFACES: "INTERFACES",
+		TYPES:      "TYPES",
+		VALUES:     "VALUES",
+		FUNCTIONS:  "FUNCTIONS",
+		DEFINES:    "DEFINES",
+		REFERENCES: "REFERENCES",
+		IMPLEMENTS: "IMPLEMENTS",
+	}
+
+	// knownOS are the GOOS values that a file name suffix may specify, as listed by go/build.
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+		"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+
+	// knownArch are the GOARCH values that a file name suffix may specify, as listed by go/build.
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+		"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+		"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// newanalyzer creates an analyzer with empty trees.
+func newanalyzer() *analyzer {
+	a := &analyzer{
+		trees:      make([]tree, TREES),
+		builds:     map[string]built{},
+		files:      map[string]map[string]struct{}{},
+		exports:    map[string]map[string]int{},
+		generics:   map[string]struct{}{},
+		origins:    map[string]map[string]token.Position{},
+		counts:     map[string]int{},
+		usage:      map[string]map[string]*imported{},
+		cohesion:   map[string]int{},
+		positions:  map[TREE]tree{DEFINES: {}, REFERENCES: {}},
+		doconly:    map[string]struct{}{},
+		production: map[string]map[string]struct{}{},
+		failures:   map[string]error{},
+		parsedDirs: map[string]struct{}{},
+		commands:   map[string]struct{}{},
+		pkgnames:   map[string]string{},
+		dotted:     map[string]struct{}{},
+		highlights: map[string]string{},
+		palette:    map[string]string{},
+		ranks:      map[string]int{},
+		fset:       token.NewFileSet(),
+		skips:      maps.Clone(skipdirs),
+	}
+	for _, skip := range strings.Split(flags.skip, ",") {
+		if skip = strings.TrimSpace(skip); skip != "" {
+			a.skips[skip] = struct{}{}
+		}
+	}
+	for i := range a.trees {
+		a.trees[i] = tree{}
+	}
+	a.imps = a.trees[IMPORTS]
+	a.ifcs = a.trees[INTERFACES]
+	a.typs = a.trees[TYPES]
+	a.vals = a.trees[VALUES]
+	a.fncs = a.trees[FUNCTIONS]
+	a.defs = a.trees[DEFINES]
+	a.refs = a.trees[REFERENCES]
+	a.sets = a.trees[IMPLEMENTS]
+	return a
+}
+
+// path determines the location of a node.
+func (v visitor) path(node ast.Node) string {
+	pth := unversion(v.fset.File(node.Pos()).Name())
+	if ext := path.Ext(pth); ext == ".go" {
+		pth = path.Dir(pth)
+	}
+	return pth
+}
+
+// Visit evaluates each node of the AST.
+func (v visitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return nil
+	}
+
+	switch node := node.(type) {
+
+	// STATEMENTS
+	case *ast.AssignStmt,
+		*ast.BadStmt,
+		*ast.BlockStmt,
+		*ast.BranchStmt,
+		*ast.CaseClause,
+		*ast.CommClause,
+		*ast.DeclStmt,
+		*ast.DeferStmt,
+		*ast.EmptyStmt,
+		*ast.ExprStmt,
+		*ast.ForStmt,
+		*ast.GoStmt,
+		*ast.IfStmt,
+		*ast.IncDecStmt,
+		*ast.LabeledStmt,
+		*ast.RangeStmt,
+		*ast.ReturnStmt,
+		*ast.SelectStmt,
+		*ast.SendStmt,
+		*ast.SwitchStmt,
+		*ast.TypeSwitchStmt:
+
+	case ast.Stmt: // put this last after all the explicit statement types
+		panic(fmt.Errorf("unexpected stmt type %T %[1]s", node))
+
+	// IDENTITY EXPRESSION
+	case *ast.Ident:
+		addRef(v, "", node)
+		addUse(v, node)
+
+	// LITERAL EXPRESSIONS
+	case *ast.BasicLit,
+		*ast.CompositeLit,
+		*ast.Ellipsis,
+		*ast.FuncLit:
+
+	// TYPE EXPRESSIONS
+	case *ast.ArrayType,
+		*ast.ChanType,
+		*ast.FuncType,
+		*ast.InterfaceType,
+		*ast.MapType,
+		*ast.StructType:
+
+	// COMPLEX EXPRESSIONS
+	case *ast.BinaryExpr,
+		*ast.CallExpr,
+		*ast.IndexExpr,
+		*ast.IndexListExpr,
+		*ast.KeyValueExpr,
+		*ast.ParenExpr,
+		*ast.SliceExpr,
+		*ast.StarExpr,
+		*ast.TypeAssertExpr,
+		*ast.UnaryExpr:
+
+	case *ast.SelectorExpr:
+		addRef(v, types.ExprString(node.X), node.Sel)
+		ast.Walk(v, node.X)
+		return nil // a selector's identifier is not a reference within the package
+
+	case ast.Expr: // put this last after all the explicit expression types
+		panic(fmt.Errorf("unexpected expr type %T %[1]s", node))
+
+	// SPECS
+	case *ast.ImportSpec:
+		if _, ok := v.skipped(strings.Trim(node.Path.Value, `"`)); ok {
+			return nil
+		}
+		addImp(v, node)
+
+	case *ast.TypeSpec:
+		addTyp(v, node)
+
+	case *ast.ValueSpec:
+		addVal(v, node)
+
+	case ast.Spec:
+		panic(fmt.Errorf("unexpected spec type %T %[1]s", node))
+
+	// NODES
+	case *ast.Package: // parse has removed the files excluded by build constraints
+		v.qual = node.Name
+		v.decls = map[string]struct{}{}
+		var dir string
+		var decls int
+		for pth, file := range node.Files {
+			decls += len(file.Decls)
+			pth := unversion(pth)
+			dir = path.Dir(pth)
+			v.addFile(dir, pth)
+			if flags.qualify == "path" {
+				v.qual = importpath(dir)
+			}
+			for name := range file.Scope.Objects {
+				v.decls[name] = struct{}{}
+			}
+		}
+		if decls == 0 && dir != "" {
+			v.doconly[dir] = struct{}{}
+		}
+
+	case *ast.File: // the file's nodes share its alias tables
+		v.aliases = map[string]string{}
+		v.importing = map[string]string{}
+		v.dots = map[string]string{}
+		v.unresolved = map[*ast.Ident]struct{}{}
+		for _, id := range node.Unresolved {
+			v.unresolved[id] = struct{}{}
+		}
+
+	case *ast.FuncDecl:
+		addFnc(v, node)
+
+	case *ast.CommentGroup,
+		*ast.Comment,
+		*ast.FieldList,
+		*ast.Field,
+		*ast.GenDecl:
+
+	default:
+		panic(fmt.Errorf("unexpected node type %T %[1]s", node))
+	}
+
+	return v
+}
+
+// gobuild evaluates a file's build constraints to determine whether to parse it,
+// recording the constraint and the decision for the -explain-build report.
+func (a *analyzer) gobuild(pth string, file *ast.File) bool {
+	b := evaluate(pth, file)
+	if pth != "" {
+		a.builds[pth] = b
+	}
+	return b.keep
+}
+
+// evaluate determines a file's build constraint and whether it is satisfied.
+func evaluate(pth string, file *ast.File) built {
+	if !flags.cgo {
+		for _, imp := range file.Imports {
+			if imp.Path.Value == `"C"` {
+				return built{constraint: `import "C"`, keep: false} // the go tool excludes files that use cgo when cgo is disabled
+			}
+		}
+	}
+
+	for _, group := range file.Comments { // look for go:build
+		if group.Pos() > file.Package {
+			break // skip comments after the package statement, e.g. a cgo preamble
+		}
+		for _, comment := range group.List {
+			if constraint.IsGoBuild(comment.Text) {
+				expr, _ := constraint.Parse(comment.Text)
+				return built{constraint: comment.Text, keep: expr.Eval(satisfied)}
+			}
+		}
+	}
+
+	if pth == "" {
+		return built{keep: true}
+	}
+
+	// create build constraint from file name
+	if s := filetags(path.Base(pth)); len(s) == 0 { // no constraints in file name
+		return built{keep: true}
+	} else { // evaluate constraints in file name
+		expr, _ := constraint.Parse("//go:build " + s)
+		return built{constraint: s + " (file name)", keep: expr.Eval(satisfied)}
+	}
+}
+
+// filetags derives the build constraint expression of a file name's GOOS and GOARCH suffixes,
+// e.g. name_linux_amd64.go, name_windows.go, or name_arm64_test.go. As for the go tool, only
+// known operating systems and architectures are constraints, so my_file.go has none.
+func filetags(name string) string {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, path.Ext(name)), "_test")
+	l := strings.Split(name, "_")
+	if n := len(l); n > 2 && knownOS[l[n-2]] && knownArch[l[n-1]] {
+		return l[n-2] + " && " + l[n-1]
+	} else if n > 1 && (knownOS[l[n-1]] || knownArch[l[n-1]]) {
+		return l[n-1]
+	}
+	return ""
+}
+
+// satisfied reports whether a build tag is satisfied by the build configuration.
+func satisfied(tag string) bool {
+	return tag == flags.goos ||
+		tag == flags.goarch ||
+		tag == "linux" && flags.goos == "android" ||
+		tag == "darwin" && flags.goos == "ios" ||
+		tag == "solaris" && flags.goos == "illumos" ||
+		tag == "cgo" && flags.cgo ||
+		tagged(tag)
+}
+
+// tagged reports whether a build tag is one of the -tags.
+func tagged(tag string) bool {
+	for _, t := range strings.Split(flags.tags, ",") {
+		if strings.TrimSpace(t) == tag && tag != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// addImp adds an import to the list of imports.
+func addImp(v visitor, node *ast.ImportSpec) {
+	pth := strings.Trim(node.Path.Value, "\"")
+	if pth == "C" { // skip cgo's pseudo-package; the file's other imports are separate specs
+		return
+	}
+
+	// convert import path to local directory path
+	var abs string
+	if wd, ok := workspacedir(pth); ok { // package in another module of the workspace
+		abs = wd
+	} else if rel, err := gocore.Subdir(gomod, pth); err == nil { // import path within the module path, not its directory
+		abs = path.Join(dirmod, rel)
+	} else if _, err := os.Stat(path.Join(dirstd, pth)); err == nil { // std package
+		abs = path.Join(dirstd, pth)
+	} else {
+		abs = path.Join(dirimps, pth) // package from imports
+	}
+
+	pkg := v.declname(abs, pth) // an alias maps to the name that the package declares
+
+	var alias string
+	if node.Name == nil {
+		alias = pkg
+	} else {
+		alias = node.Name.Name
+	}
+	if flags.qualify == "path" {
+		pkg = pth
+	}
+	v.aliases[alias] = pkg
+	v.importing[alias] = pth
+	if alias == "." {
+		v.dots[pkg] = pth
+	}
+	v.imps.Add(pkg, abs)
+
+	dir := v.path(node)
+	if _, ok := v.usage[dir]; !ok {
+		v.usage[dir] = map[string]*imported{}
+	}
+	if _, ok := v.usage[dir][pth]; !ok {
+		v.usage[dir][pth] = &imported{alias: alias}
+	}
+}
+
+// addTyp adds a type to the typs or ifcs list.
+func addTyp(v visitor, node *ast.TypeSpec) {
+	if !ast.IsExported(node.Name.Name) {
+		return
+	}
+	addDef(v, node.Name)
+	if _, ok := node.Type.(*ast.InterfaceType); ok {
+		addExp(v, node.Name, "interfaces")
+	} else {
+		addExp(v, node.Name, "types")
+	}
+
+	name := v.qual + "." + node.Name.Name
+	if node.Assign.IsValid() { // type alias is the same type, not a definition
+		v.typs.Add(name, "= "+types.ExprString(node.Type))
+		return
+	}
+
+	if node.TypeParams != nil && len(node.TypeParams.List) > 0 {
+		v.generics[name] = struct{}{}
+	}
+
+	switch expr := node.Type.(type) {
+	case *ast.InterfaceType:
+		addIfc(v, name, expr)
+	case *ast.StructType:
+		v.addStr(name, expr)
+	case *ast.CompositeLit:
+		lit := types.ExprString(expr.Type)
+		for _, elt := range expr.Elts {
+			v.typs.Add(name, lit, types.ExprString(elt))
+		}
+	default:
+		v.typs.Add(name, types.ExprString(expr))
+	}
+}
+
+// qualified separates a qualified identifier into its qualifier and name.
+func qualified(id string) (string, string) {
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		return id[:i], id[i+1:]
+	}
+	return "", id
+}
+
+// addIfc adds an interface and its methods to the list of interfaces.
+func addIfc(v visitor, name string, node *ast.InterfaceType) {
+	for _, mth := range node.Methods.List {
+		if len(mth.Names) == 0 {
+			// embedded interface type
+			dt := types.ExprString(mth.Type)
+			if !strings.Contains(dt, ".") && ast.IsExported(dt) {
+				dt = v.qual + "." + dt // interface is in this package
+			}
+			v.ifcs.Add(name, dt)
+		} else {
+			for _, id := range mth.Names {
+				v.ifcs.Add(name, id.Name+signature(mth.Type.(*ast.FuncType)))
+			}
+		}
+	}
+}
+
+// addStr adds a structure declaration to the list of types.
+func (a *analyzer) addStr(name string, node *ast.StructType) {
+	for _, fld := range node.Fields.List {
+		names := make([]string, len(fld.Names))
+		for i, id := range fld.Names {
+			names[i] = id.Name
+		}
+		line := strings.Join(names, ", ")
+		if fnc, ok := fld.Type.(*ast.FuncType); ok {
+			line += signature(fnc)
+		} else {
+			if len(line) > 0 {
+				line += " "
+			}
+			expr := fld.Type
+			if s, ok := expr.(*ast.StarExpr); ok {
+				expr = s.X
+			}
+			line += types.ExprString(expr)
+		}
+		if ast.IsExported(line) {
+			a.typs.Add(name, line)
+		}
+	}
+}
+
+// addVal adds a value to the list of values.
+func addVal(v visitor, node *ast.ValueSpec) {
+	for _, id := range node.Names {
+		if !ast.IsExported(id.Name) {
+			continue
+		}
+		addDef(v, id)
+		addExp(v, id, "values")
+
+		name := v.qual + "." + id.Name
+		for _, val := range node.Values {
+			v.vals.Add(name, types.ExprString(val))
+		}
+	}
+}
+
+// addFnc adds function to the functions list or method to a type in the types list
+func addFnc(v visitor, node *ast.FuncDecl) {
+	if !ast.IsExported(node.Name.Name) {
+		return
+	}
+	addDef(v, node.Name)
+
+	if node.Recv == nil || len(node.Recv.List) == 0 {
+		addExp(v, node.Name, "functions")
+		v.fncs.Add(v.qual + "." + node.Name.Name + signature(node.Type))
+	} else {
+		expr := node.Recv.List[0].Type
+		if s, ok := expr.(*ast.StarExpr); ok {
+			expr = s.X
+		}
+		switch x := expr.(type) { // generic receiver, e.g. Stack[T], keys off its type name
+		case *ast.IndexExpr:
+			expr = x.X
+		case *ast.IndexListExpr:
+			expr = x.X
+		}
+		name := types.ExprString(expr) // methods key off receiver type
+		if !ast.IsExported(name) {
+			return
+		}
+		addExp(v, node.Name, "methods")
+		v.typs.Add(v.qual+"."+name, node.Name.Name+signature(node.Type))
+	}
+}
+
+// addDef adds the location where an identifier is defined.
+func addDef(v visitor, id *ast.Ident) {
+	v.defs.Add(v.qual+"."+id.Name, v.path(id))
+	if flags.positions {
+		v.positions[DEFINES].Add(v.qual+"."+id.Name, v.path(id), v.fset.Position(id.Pos()).String())
+	}
+}
+
+// addFile records a file that contributes to the package of a directory.
+func (a *analyzer) addFile(dir, pth string) {
+	if _, ok := a.files[dir]; !ok {
+		a.files[dir] = map[string]struct{}{}
+	}
+	a.files[dir][pth] = struct{}{}
+}
+
+// addExp counts an exported identifier of a package by category.
+func addExp(v visitor, id *ast.Ident, category string) {
+	dir := v.path(id)
+	if _, ok := v.exports[dir]; !ok {
+		v.exports[dir] = map[string]int{}
+	}
+	v.exports[dir][category]++
+}
+
+// addRef adds the location where an identifier is referenced. The qualifier of a bare
+// identifier is "": if neither the package nor the file declares it, and it is not a key of a
+// composite literal or the name of a method, it is a reference to one of the packages that the
+// file imports with a dot.
+func addRef(v visitor, qualifier string, id *ast.Ident) {
+	if !ast.IsExported(id.Name) {
+		return
+	}
+	if qualifier != "" {
+		if pkg := v.aliases[qualifier]; pkg != "" {
+			addSel(v, pkg, v.importing[qualifier], id)
+		}
+		return
+	}
+	if _, ok := v.decls[id.Name]; ok {
+		return
+	}
+	if _, ok := v.unresolved[id]; !ok {
+		return
+	}
+	for pkg, pth := range v.dots {
+		v.dotted[v.path(id)+" "+pkg+"."+id.Name] = struct{}{}
+		addSel(v, pkg, pth, id)
+	}
+}
+
+// addSel adds the location where an identifier of an imported package is referenced.
+func addSel(v visitor, pkg, pth string, id *ast.Ident) {
+	v.refs.Add(pkg+"."+id.Name, v.path(id))
+	v.usage[v.path(id)][pth].uses++
+	v.counts[v.path(id)+" "+pkg+"."+id.Name]++
+	if flags.origins || flags.since != "" {
+		addOrigin(v, pkg+"."+id.Name, id)
+	}
+	if flags.positions {
+		v.positions[REFERENCES].Add(pkg+"."+id.Name, v.path(id), v.fset.Position(id.Pos()).String())
+	}
+	if flags.tests && !strings.HasSuffix(v.fset.File(id.Pos()).Name(), "_test.go") {
+		dir := v.path(id)
+		if _, ok := v.production[dir]; !ok {
+			v.production[dir] = map[string]struct{}{}
+		}
+		v.production[dir][pkg+"."+id.Name] = struct{}{}
+	}
+}
+
+// addOrigin keeps the first position in a package's files where an identifier is referenced.
+func addOrigin(v visitor, ref string, id *ast.Ident) {
+	dir := v.path(id)
+	pos := v.fset.Position(id.Pos())
+	if _, ok := v.origins[dir]; !ok {
+		v.origins[dir] = map[string]token.Position{}
+	}
+	if org, ok := v.origins[dir][ref]; !ok || before(pos, org) {
+		v.origins[dir][ref] = pos
+	}
+}
+
+// before orders source positions by file, line, and column.
+func before(a, b token.Position) bool {
+	return a.Filename < b.Filename ||
+		a.Filename == b.Filename && (a.Line < b.Line ||
+			a.Line == b.Line && a.Column < b.Column)
+}
+
+// addUse counts a reference within a package to one of its package level declarations.
+func addUse(v visitor, id *ast.Ident) {
+	if _, ok := v.decls[id.Name]; !ok {
+		return
+	}
+	if id.Obj != nil && (id.Obj.Pos() == id.Pos() || !v.declared(id.Obj)) { // declaration or local
+		return
+	}
+	v.cohesion[v.path(id)]++
+}
+
+// declared reports whether an object is one of the package level declarations.
+func (v visitor) declared(obj *ast.Object) bool {
+	for _, file := range v.pkg.Files {
+		if file.Scope.Objects[obj.Name] == obj {
+			return true
+		}
+	}
+	return false
+}
+
+// signature formats the parameter and result types of a function or method.
+func signature(node *ast.FuncType) string {
+	parms := "(" + typelist(node.Params) + ")"
+	rslts := typelist(node.Results)
+	if rslts != "" {
+		parms += " "
+	}
+	if strings.Contains(rslts, ",") {
+		rslts = " (" + rslts + ")"
+	}
+
+	return parms + rslts
+}
+
+// typelist reports the types of a list of fields.
+func typelist(flds *ast.FieldList) string {
+	if flds == nil {
+		return ""
+	}
+	var typs []string
+	for _, fld := range flds.List {
+		typ := types.ExprString(fld.Type)
+		for range max(len(fld.Names), 1) { // one per named parameter, or the unnamed parameter
+			typs = append(typs, typ)
+		}
+	}
+
+	return strings.Join(typs, ", ")
+}
@@ -0,0 +1,168 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddImpCgo(t *testing.T) {
+	cgo := flags.cgo
+	defer func() { flags.cgo = cgo }()
+
+	flags.cgo = true
+	r := analyze(t, "cgo", Options{})
+	if _, ok := r.Trees["IMPORTS"]["fmt"]; !ok {
+		t.Errorf("IMPORTS = %v, want fmt", r.Trees["IMPORTS"])
+	}
+	if _, ok := r.Trees["IMPORTS"]["C"]; ok {
+		t.Errorf("IMPORTS includes the C pseudo-package")
+	}
+	if g := graphviz(t, r); !strings.Contains(g, `"std: fmt" -> "example.com/cgo: example.com/cgo" [dir=back`) {
+		t.Errorf("Graphviz() lacks the dependency of the cgo package on fmt:\n%s", g)
+	}
+
+	flags.cgo = false // the go tool excludes the file
+	if r := analyze(t, "cgo", Options{}); len(r.Trees["IMPORTS"]) > 0 {
+		t.Errorf("IMPORTS without cgo = %v, want none", r.Trees["IMPORTS"])
+	}
+}
+
+func TestFiletags(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		tags string
+	}{
+		{"name_linux_amd64.go", "linux && amd64"},
+		{"name_windows.go", "windows"},
+		{"name_arm64_test.go", "arm64"},
+		{"name_linux_amd64_test.go", "linux && amd64"},
+		{"my_file.go", ""},
+		{"linux.go", ""},
+		{"name_amd64_linux.go", "linux"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if tags := filetags(test.name); tags != test.tags {
+				t.Errorf("filetags(%q) = %q, want %q", test.name, tags, test.tags)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	goos, goarch := flags.goos, flags.goarch
+	defer func() { flags.goos, flags.goarch = goos, goarch }()
+
+	for _, test := range []struct {
+		name   string
+		src    string
+		goos   string
+		goarch string
+		keep   bool
+	}{
+		{"go:build arch", "//go:build amd64\n\npackage p\n", "linux", "amd64", true},
+		{"go:build other arch", "//go:build amd64\n\npackage p\n", "linux", "arm64", false},
+		{"go:build os and arch", "//go:build linux && arm64\n\npackage p\n", "linux", "arm64", true},
+		{"go:build negated arch", "//go:build !arm64\n\npackage p\n", "darwin", "arm64", false},
+		{"go:build android implies linux", "//go:build linux\n\npackage p\n", "android", "arm64", true},
+		{"go:build after package", "package p\n\n//go:build ignore\n", "linux", "amd64", true},
+		{"name_linux_amd64.go", "package p\n", "linux", "amd64", true},
+		{"name_linux_amd64.go", "package p\n", "linux", "arm64", false},
+		{"name_linux_amd64.go", "package p\n", "darwin", "amd64", false},
+		{"name_arm64_test.go", "package p\n", "darwin", "arm64", true},
+		{"name_arm64_test.go", "package p\n", "darwin", "amd64", false},
+		{"my_file.go", "package p\n", "windows", "386", true},
+		{"name_arm64.go", "//go:build ignore\n\npackage p\n", "linux", "arm64", false}, // go:build decides
+	} {
+		t.Run(test.name+" "+test.goos+"/"+test.goarch, func(t *testing.T) {
+			file, err := parser.ParseFile(token.NewFileSet(), test.name, test.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			flags.goos, flags.goarch = test.goos, test.goarch
+			pth := "/src/p/" + test.name
+			if strings.HasPrefix(test.name, "go:build") {
+				pth = "" // decided by the comment alone
+			}
+			if b := evaluate(pth, file); b.keep != test.keep {
+				t.Errorf("evaluate(%q) = %+v, want keep %t", test.name, b, test.keep)
+			}
+		})
+	}
+}
+
+func TestAnalyzeGOOS(t *testing.T) {
+	dir := fixture(t, "platform")
+	for _, test := range []struct {
+		goos     string
+		lin, win bool
+	}{
+		{"linux", true, false},
+		{"android", true, false},
+		{"windows", false, true},
+		{"darwin", false, false},
+	} {
+		t.Run(test.goos, func(t *testing.T) {
+			r := analyze(t, "platform", Options{GOOS: test.goos})
+			if lin := referenced(r, dir, "lin.F", "x", "lin"); lin != test.lin {
+				t.Errorf("REFERENCES lin.F from x = %t, want %t", lin, test.lin)
+			}
+			if win := referenced(r, dir, "win.F", "x", "win"); win != test.win {
+				t.Errorf("REFERENCES win.F from x = %t, want %t", win, test.win)
+			}
+		})
+	}
+}
+
+// referenced reports whether the REFERENCES tree records a reference from a package directory
+// of a fixture to an identifier defined by another.
+func referenced(r *Result, dir, ref, from, to string) bool {
+	_, ok := r.Trees["REFERENCES"][ref][filepath.Join(dir, from)][filepath.Join(dir, to)]
+	return ok
+}
+
+func TestAddRefAliasAndDot(t *testing.T) {
+	r := analyze(t, "imports", Options{})
+	dir := fixture(t, "imports")
+	for _, test := range []struct {
+		name     string
+		ref      string
+		from, to string
+	}{
+		{"aliased import", "a.F", "b", "a"},
+		{"aliased import type", "a.T", "b", "a"},
+		{"dot import", "a.F", "c", "a"},
+		{"dot import type", "a.T", "c", "a"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if !referenced(r, dir, test.ref, test.from, test.to) {
+				t.Errorf("REFERENCES %s = %v, want from %s to %s", test.ref, r.Trees["REFERENCES"][test.ref], test.from, test.to)
+			}
+			if _, ok := r.lks[link{from: filepath.Join(dir, test.from), to: filepath.Join(dir, test.to)}]; !ok {
+				t.Errorf("links() lacks the dependency of %s on %s", test.from, test.to)
+			}
+		})
+	}
+}
+
+func TestAddRefDotHelper(t *testing.T) {
+	r := analyze(t, "imports", Options{})
+	dir := fixture(t, "imports")
+	if _, ok := r.Trees["REFERENCES"]["strings.ToUpper"][filepath.Join(dir, "c")]; !ok {
+		t.Errorf("REFERENCES strings.ToUpper = %v, want from c", r.Trees["REFERENCES"]["strings.ToUpper"])
+	}
+	for _, ref := range []string{
+		"a.V2",            // declared by c
+		"strings.F",       // defined by a, the other dot import
+		"a.N",             // the key of a composite literal, a field of T
+		"strings.ToLower", // the name of a method of c
+	} {
+		if refs, ok := r.Trees["REFERENCES"][ref]; ok {
+			t.Errorf("REFERENCES %s = %v, want none", ref, refs)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+var (
+	// workspace maps the paths of the other modules of the module's go.work workspace to their directories.
+	workspace = map[string]string{}
+)
+
+// loadworkspace records the other modules of the go.work workspace of the module, as GOWORK
+// specifies or as found in the module's directory or above, so that their packages are
+// analyzed as the module's rather than as imports, each in its own top-level subgraph.
+func loadworkspace() error {
+	work := os.Getenv("GOWORK")
+	if work == "off" {
+		return nil
+	}
+	if work == "" {
+		for dir := dirmod; ; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(dir, "go.work")); err == nil {
+				work = filepath.Join(dir, "go.work")
+				break
+			}
+			if dir == filepath.Dir(dir) {
+				return nil
+			}
+		}
+	}
+
+	data, err := os.ReadFile(work)
+	if err != nil {
+		return gocore.Error("go.work", err)
+	}
+	var block bool
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		var use string
+		switch {
+		case len(fields) == 0:
+		case block && fields[0] == ")":
+			block = false
+		case block:
+			use = fields[0]
+		case fields[0] == "use" && len(fields) == 2 && fields[1] == "(":
+			block = true
+		case fields[0] == "use" && len(fields) == 2:
+			use = fields[1]
+		}
+		if use == "" {
+			continue
+		}
+		dir := filepath.Join(filepath.Dir(work), filepath.FromSlash(strings.Trim(use, `"`)))
+		if dir == dirmod {
+			continue
+		}
+		if mod := modulename(dir); mod != "" {
+			workspace[mod] = dir
+			dirmap[dir] = mod
+		}
+	}
+	return nil
+}
+
+// modulename reads the module path of the module directive of a module directory's go.mod.
+func modulename(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// workspaces orders the paths of the other modules of the workspace.
+func workspaces() []string {
+	var mods []string
+	for mod := range workspace {
+		mods = append(mods, mod)
+	}
+	sort.Strings(mods)
+	return mods
+}
+
+// workspacedir resolves an import path to its package directory within the other modules of
+// the workspace, preferring the longest module path of nested modules, including the module's.
+func workspacedir(pth string) (string, bool) {
+	mod := ""
+	if _, err := gocore.Subdir(gomod, pth); err == nil {
+		mod = gomod
+	}
+	for m := range workspace {
+		if _, err := gocore.Subdir(m, pth); err == nil && len(m) > len(mod) {
+			mod = m
+		}
+	}
+	if _, ok := workspace[mod]; !ok {
+		return "", false
+	}
+	rel, _ := gocore.Subdir(mod, pth)
+	return filepath.Join(workspace[mod], filepath.FromSlash(rel)), true
+}
+
+// workspacepath resolves a package directory to its import path within the other modules of
+// the workspace, preferring the longest directory of nested modules, including the module's.
+func workspacepath(abs string) (string, bool) {
+	dir := ""
+	if _, err := gocore.Subdir(dirmod, abs); err == nil {
+		dir = dirmod
+	}
+	for _, wd := range workspace {
+		if _, err := gocore.Subdir(wd, abs); err == nil && len(wd) > len(dir) {
+			dir = wd
+		}
+	}
+	if dir == dirmod || dir == "" {
+		return "", false
+	}
+	rel, _ := gocore.Subdir(dir, abs)
+	return path.Join(dirmap[dir], filepath.ToSlash(rel)), true
+}
+
+// member reports whether a top-level subgraph is that of the module or of another module of its workspace.
+func member(tg string) bool {
+	_, ok := workspace[tg]
+	return tg == gomod || ok
+}
+
+// inmodule reports whether a package directory is within the module or the other modules of its workspace.
+func inmodule(dir string) bool {
+	if _, err := gocore.Subdir(dirmod, dir); err == nil {
+		return true
+	}
+	for _, wd := range workspace {
+		if _, err := gocore.Subdir(wd, dir); err == nil {
+			return true
+		}
+	}
+	return false
+}
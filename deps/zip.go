@@ -0,0 +1,91 @@
+// Copyright © 2023 The Gomon Project.
+
+package deps
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zosmac/gocore"
+)
+
+// unzip extracts a module zip, as the go tool downloads to the module cache, into a temporary
+// directory. It returns the temporary directory to remove afterward, the directory of the
+// module's root, and the module's path from the zip's module@version prefix. The root omits
+// the version, which would otherwise be stripped from the module's package directories.
+func unzip(name string) (string, string, string, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return "", "", "", gocore.Error("zip", err)
+	}
+	defer zr.Close()
+
+	var mod, prefix string
+	for _, f := range zr.File {
+		if m, vers, ok := strings.Cut(f.Name, "@"); ok {
+			v, _, _ := strings.Cut(vers, "/")
+			mod, prefix = m, m+"@"+v+"/"
+			break
+		}
+	}
+	if mod == "" {
+		return "", "", "", gocore.Error("zip", errors.New("no module@version prefix"), map[string]string{
+			"zip": name,
+		})
+	}
+
+	tmp, err := os.MkdirTemp("", "godep-zip-")
+	if err != nil {
+		return "", "", "", gocore.Error("zip", err)
+	}
+	root := filepath.Join(tmp, filepath.FromSlash(mod))
+	for _, f := range zr.File {
+		rel, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok {
+			continue // outside of the module
+		}
+		if err := extract(root, rel, f); err != nil {
+			os.RemoveAll(tmp)
+			return "", "", "", gocore.Error("zip", err, map[string]string{
+				"file": f.Name,
+			})
+		}
+	}
+	return tmp, root, mod, nil
+}
+
+// extract writes a file of a zip to its relative name beneath a directory, rejecting names that escape it.
+func extract(dir, rel string, f *zip.File) error {
+	if rel == "" {
+		return os.MkdirAll(dir, 0o755)
+	}
+	if !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return errors.New("invalid file name")
+	}
+	name := filepath.Join(dir, filepath.FromSlash(rel))
+	if f.FileInfo().IsDir() {
+		return os.MkdirAll(name, 0o755)
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	w, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, r)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -7,4 +7,16 @@ Package godep defines the godep command that examines the source files of a Go l
 module. It produces a node graph of the dependencies of the module's packages on packages
 of the Go standard library, of imports, and of those that are vendored. Godep uses the
 Graphvis dot command to produce the nodegraph.
+
+Only the primary artifact, i.e. the rendered graph, its Graphviz source, the JSON of the
+trees, or the comparison of two module checkouts, is written to standard output, so that it
+may be piped to another command. The report of the trees, diagnostics, and errors are always
+written to standard error.
+
+When the module is one of a go.work workspace, as found in the module's directory or above
+or as GOWORK names, the packages of the workspace's other modules are analyzed with the
+module's, each module in its own top-level subgraph. GOWORK=off analyzes the module alone.
+
+The analysis is implemented by package github.com/zosmac/godep/deps, which other programs
+may import to analyze a module and serialize its graph without running the godep command.
 */
@@ -2,12 +2,14 @@ module github.com/zosmac/godep
 
 go 1.23.4
 
-require github.com/zosmac/gocore v0.0.0-20240328235524-b820cfbd817e
+require (
+	github.com/zosmac/gocore v0.0.0-20240328235524-b820cfbd817e
+	golang.org/x/tools v0.19.0
+)
 
 require (
 	github.com/StackExchange/wmi v1.2.1 // indirect
 	github.com/go-ole/go-ole v1.2.5 // indirect
 	golang.org/x/mod v0.16.0 // indirect
 	golang.org/x/sys v0.18.0 // indirect
-	golang.org/x/tools v0.19.0 // indirect
 )